@@ -0,0 +1,85 @@
+// Command goi18n-gen generates Go source from locale files. The locales mode generates locales to be passed to
+// i18n.InitLocales instead of i18n.Init, so locale files are neither embedded nor parsed at runtime. The messages
+// and errors modes generate typed functions and errors per key of the reference language.
+//
+//	//go:generate go run github.com/derfenix/goi18n/cmd/goi18n-gen -dir . -out locales_gen.go
+//	//go:generate go run github.com/derfenix/goi18n/cmd/goi18n-gen -dir . -mode messages -lang en -pkg msgs -out msgs/msgs_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"github.com/derfenix/goi18n/internal"
+)
+
+// dirFS adds fs.ReadDirFS to os.DirFS.
+type dirFS struct {
+	fs.FS
+}
+
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(d.FS, name)
+}
+
+func main() {
+	var (
+		dir      = flag.String("dir", ".", "directory with locale files")
+		layout   = flag.String("layout", "", "comma separated patterns of locale file paths, e.g. locales/{lang}.json")
+		pkg      = flag.String("pkg", os.Getenv("GOPACKAGE"), "package of the generated file")
+		out      = flag.String("out", "locales_gen.go", "generated file")
+		variable = flag.String("var", "Locales", "name of the generated variable")
+		mode     = flag.String("mode", "locales", "what to generate: locales, messages or errors")
+		lang     = flag.String("lang", "en", "reference language of messages and errors")
+	)
+
+	flag.Parse()
+
+	if *pkg == "" {
+		log.Fatal("package is not set, use -pkg")
+	}
+
+	var patterns []string
+	if *layout != "" {
+		patterns = strings.Split(*layout, ",")
+	}
+
+	locales, err := internal.ReadLocales(dirFS{FS: os.DirFS(*dir)}, patterns...)
+	if err != nil {
+		log.Fatalf("read locales: %v", err)
+	}
+
+	var src bytes.Buffer
+
+	switch *mode {
+	case "locales":
+		err = internal.GenerateGo(&src, *pkg, *variable, locales)
+	case "messages", "errors":
+		reference, parseErr := language.Parse(*lang)
+		if parseErr != nil {
+			log.Fatalf("parse language %s: %v", *lang, parseErr)
+		}
+
+		err = internal.GenerateAccessors(&src, internal.AccessorOptions{
+			Package:  *pkg,
+			Language: reference,
+			Errors:   *mode == "errors",
+		}, locales)
+	default:
+		log.Fatalf("unknown mode %s", *mode)
+	}
+
+	if err != nil {
+		log.Fatalf("generate: %v", err)
+	}
+
+	if err := os.WriteFile(*out, src.Bytes(), 0o644); err != nil {
+		log.Fatalf("write %s: %v", *out, err)
+	}
+}
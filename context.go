@@ -5,6 +5,8 @@ import (
 
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
+
+	"github.com/derfenix/goi18n/internal"
 )
 
 type i18nCtxType uint8
@@ -12,25 +14,56 @@ type i18nCtxType uint8
 const (
 	langCtxKey i18nCtxType = iota
 	printerCtxKey
+	tenantCtxKey
 )
 
 func ContextWithLang(ctx context.Context, lang language.Tag) context.Context {
 	ctx = context.WithValue(ctx, langCtxKey, lang)
-	ctx = context.WithValue(ctx, printerCtxKey, GetPrinter(lang))
+	ctx = context.WithValue(ctx, printerCtxKey, GetTenantPrinter(TenantFromContext(ctx), lang))
+
+	return ctx
+}
+
+// ContextWithTenant makes translations of the context resolve overrides of the tenant first.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	ctx = context.WithValue(ctx, tenantCtxKey, tenant)
+	ctx = context.WithValue(ctx, printerCtxKey, GetTenantPrinter(tenant, LanguageFromContext(ctx)))
 
 	return ctx
 }
 
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantCtxKey).(string)
+
+	return tenant
+}
+
 func PrinterFromContext(ctx context.Context) *message.Printer {
+	// Overrides of the tenant could be set or removed after the printer of the context was created.
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		return GetTenantPrinter(tenant, LanguageFromContext(ctx))
+	}
+
 	if p, ok := ctx.Value(printerCtxKey).(*message.Printer); ok {
 		return p
 	}
 
-	return GetPrinter(LanguageFromContext(ctx))
+	return GetTenantPrinter(TenantFromContext(ctx), LanguageFromContext(ctx))
 }
 
 func Sprintf(ctx context.Context, val string, args ...interface{}) string {
-	return PrinterFromContext(ctx).Sprintf(val, args...)
+	return PrinterFromContext(ctx).Sprintf(val, prepareArgs(ctx, val, args)...)
+}
+
+// prepareArgs prepares plural arguments with digits of the context tenant, the ones its printer uses.
+func prepareArgs(ctx context.Context, key string, args []interface{}) []interface{} {
+	if builder == nil {
+		return args
+	}
+
+	lang := tenantLanguage(TenantFromContext(ctx), supportedLanguage(LanguageFromContext(ctx)))
+
+	return internal.PrepareArgs(builder, lang, key, args)
 }
 
 func LanguageFromContext(ctx context.Context) language.Tag {
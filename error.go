@@ -27,18 +27,34 @@ func NewError(key string) *Error {
 }
 
 type Error struct {
-	key    string
-	params []interface{}
+	key         string
+	params      []interface{}
+	namedParams interface{}
 }
 
 func (e *Error) Error() string {
 	return e.key
 }
 
+// Key returns the translation key of the error.
+func (e *Error) Key() string {
+	return e.key
+}
+
 func (e *Error) WithParams(params ...interface{}) *Error {
 	newErr := *e
 
 	newErr.params = params
+	newErr.namedParams = nil
+
+	return &newErr
+}
+
+func (e *Error) WithNamedParams(params interface{}) *Error {
+	newErr := *e
+
+	newErr.params = nil
+	newErr.namedParams = params
 
 	return &newErr
 }
@@ -46,9 +62,14 @@ func (e *Error) WithParams(params ...interface{}) *Error {
 func (e *Error) Translate(ctx context.Context) string {
 	printer := PrinterFromContext(ctx)
 
-	translatedParams := make([]interface{}, len(e.params))
+	params := e.params
+	if e.namedParams != nil {
+		params = NamedArgs(e.key, e.namedParams)
+	}
+
+	translatedParams := make([]interface{}, len(params))
 
-	for idx, param := range e.params {
+	for idx, param := range params {
 		switch typed := param.(type) {
 		case string:
 			translatedParams[idx] = printer.Sprintf(typed)
@@ -59,13 +80,15 @@ func (e *Error) Translate(ctx context.Context) string {
 		}
 	}
 
-	return printer.Sprintf(e.key, translatedParams...)
+	return printer.Sprintf(e.key, prepareArgs(ctx, e.key, translatedParams)...)
 }
 
 func (e *Error) Is(other error) bool {
 	switch err := other.(type) {
 	case *Error:
 		return e.key == err.key
+	case interface{ Key() string }:
+		return e.key == err.Key()
 	}
 
 	return false
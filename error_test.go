@@ -29,3 +29,21 @@ func TestTryTranslateError(t *testing.T) {
 	require.True(t, translated)
 	assert.Equal(t, "Test of the book", errorString)
 }
+
+type keyedError struct {
+	key string
+}
+
+func (e keyedError) Error() string { return e.key }
+
+func (e keyedError) Key() string { return e.key }
+
+func TestErrorIsKeyed(t *testing.T) {
+	t.Parallel()
+
+	err := NewError("test").WithParams("book")
+
+	assert.Equal(t, "test", err.Key())
+	assert.True(t, errors.Is(err, keyedError{key: "test"}))
+	assert.False(t, errors.Is(err, keyedError{key: "test plural"}))
+}
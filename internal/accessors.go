@@ -0,0 +1,302 @@
+package internal
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// AccessorOptions describes Go source generated by GenerateAccessors.
+type AccessorOptions struct {
+	Package string
+	// Language is the reference language, its messages define names and types of arguments.
+	Language language.Tag
+	// Errors generates i18n.Error variables with typed WithParams instead of functions returning strings.
+	Errors bool
+}
+
+type accessor struct {
+	name   string
+	key    string
+	text   string
+	params []accessorParam
+}
+
+type accessorParam struct {
+	name string
+	typ  string
+}
+
+// GenerateAccessors writes Go source with a key constant and a typed function or error per message of the
+// reference language, so typos in keys and wrong arguments are caught by the compiler.
+func GenerateAccessors(w io.Writer, options AccessorOptions, locales []Locale) error {
+	var messages []Message
+
+	found := false
+
+	for _, locale := range locales {
+		lang, err := language.Parse(locale.Language)
+		if err == nil && lang == options.Language {
+			messages, found = locale.Messages, true
+
+			break
+		}
+	}
+
+	if !found {
+		return errors.Errorf("no locale of reference language %s", options.Language)
+	}
+
+	accessors, err := accessorsOf(messages, options.Errors)
+	if err != nil {
+		return err
+	}
+
+	var src strings.Builder
+
+	src.WriteString("// Code generated by goi18n-gen. DO NOT EDIT.\n\n")
+	src.WriteString("package " + options.Package + "\n\n")
+	src.WriteString("import (\n\"context\"\n\ni18n \"github.com/derfenix/goi18n\"\n)\n\n")
+
+	src.WriteString("// Keys of messages.\nconst (\n")
+
+	for _, acc := range accessors {
+		src.WriteString("Key" + acc.name + " = " + strconv.Quote(acc.key) + "\n")
+	}
+
+	src.WriteString(")\n")
+
+	for _, acc := range accessors {
+		if options.Errors {
+			writeErrorAccessor(&src, acc)
+		} else {
+			writeMessageAccessor(&src, acc)
+		}
+	}
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return errors.Wrap(err, "format source")
+	}
+
+	if _, err := io.Copy(w, bytes.NewReader(formatted)); err != nil {
+		return errors.Wrap(err, "write source")
+	}
+
+	return nil
+}
+
+func writeMessageAccessor(src *strings.Builder, acc accessor) {
+	src.WriteString("\n// " + acc.name + " translates " + strconv.Quote(acc.text) + ".\n")
+	src.WriteString("func " + acc.name + "(ctx context.Context" + paramList(acc.params, true) + ") string {\n")
+	src.WriteString("return i18n.Sprintf(ctx, Key" + acc.name + paramList(acc.params, false) + ")\n}\n")
+}
+
+func writeErrorAccessor(src *strings.Builder, acc accessor) {
+	typ := acc.name + "Error"
+
+	src.WriteString("\n// " + acc.name + " is the error " + strconv.Quote(acc.text) + ".\n")
+	src.WriteString("var " + acc.name + " = " + typ + "{err: i18n.NewError(Key" + acc.name + ")}\n\n")
+	src.WriteString("type " + typ + " struct {\nerr *i18n.Error\n}\n\n")
+	src.WriteString("func (e " + typ + ") Error() string {\nreturn e.err.Error()\n}\n\n")
+	src.WriteString("func (e " + typ + ") Key() string {\nreturn e.err.Key()\n}\n\n")
+	src.WriteString("func (e " + typ + ") Translate(ctx context.Context) string {\nreturn e.err.Translate(ctx)\n}\n\n")
+	src.WriteString("func (e " + typ + ") Unwrap() error {\nreturn e.err\n}\n\n")
+	src.WriteString("func (e " + typ + ") WithParams(" + strings.TrimPrefix(paramList(acc.params, true), ", ") + ") *i18n.Error {\n")
+	src.WriteString("return e.err.WithParams(" + strings.TrimPrefix(paramList(acc.params, false), ", ") + ")\n}\n")
+}
+
+// paramList returns ", a, b" or, with types, ", a, b string", consecutive params of the same type are merged.
+func paramList(params []accessorParam, typed bool) string {
+	var list strings.Builder
+
+	for idx, param := range params {
+		list.WriteString(", " + param.name)
+
+		if typed && (idx+1 == len(params) || params[idx+1].typ != param.typ) {
+			list.WriteString(" " + param.typ)
+		}
+	}
+
+	return list.String()
+}
+
+// accessorsOf returns accessors of the messages, keys generating the same identifier are an error.
+func accessorsOf(messages []Message, errorTypes bool) ([]accessor, error) {
+	var (
+		accessors   = make([]accessor, 0, len(messages))
+		identifiers = map[string]string{}
+	)
+
+	for idx := range messages {
+		trans := messages[idx].translation()
+		if trans.isVar() {
+			continue
+		}
+
+		name := goName(trans.Key)
+		if name == "" {
+			return nil, errors.Errorf("key %q has no ASCII letters or digits to name its accessor", trans.Key)
+		}
+
+		declared := []string{name, "Key" + name}
+		if errorTypes {
+			declared = append(declared, name+"Error")
+		}
+
+		for _, ident := range declared {
+			if other, ok := identifiers[ident]; ok {
+				return nil, errors.Errorf("keys %q and %q both generate identifier %s, rename one of them", other, trans.Key, ident)
+			}
+
+			identifiers[ident] = trans.Key
+		}
+
+		text := trans.Translation
+		if trans.Plural != nil {
+			text = trans.Plural.Other
+		}
+
+		accessors = append(accessors, accessor{name: name, key: trans.Key, text: text, params: trans.params()})
+	}
+
+	sort.Slice(accessors, func(i, j int) bool { return accessors[i].key < accessors[j].key })
+
+	return accessors, nil
+}
+
+// params returns arguments of the message, types are defined by verbs and plural selections. Named
+// placeholders take the type of their verb, e.g. string for {from:s}, and interface{} without one.
+func (t *Translation) params() []accessorParam {
+	var types []string
+
+	grow := func(n int) {
+		for len(types) < n {
+			types = append(types, "")
+		}
+	}
+
+	addVerbs := func(text string) {
+		compiled, err := t.text(text)
+		if err != nil {
+			return
+		}
+
+		verbs := argVerbs(compiled)
+		grow(len(verbs))
+
+		for idx, verb := range verbs {
+			// %v leaves the type to other verbs of the argument and to plural selections.
+			if verb == "" || types[idx] != "" {
+				continue
+			}
+
+			if typ := verbType(verb); typ != "interface{}" {
+				types[idx] = typ
+			}
+		}
+	}
+
+	grow(len(t.Placeholders))
+
+	if t.Plural != nil {
+		addVerbs(t.Plural.Other)
+	} else {
+		addVerbs(t.Translation)
+	}
+
+	for _, name := range t.nestedNames() {
+		addVerbs(t.Plurals[name].Other)
+	}
+
+	for _, plural := range t.pluralArgs() {
+		if plural.arg <= 0 {
+			continue
+		}
+
+		grow(plural.arg)
+
+		switch {
+		case plural.digits != nil:
+			types[plural.arg-1] = "float64"
+		case types[plural.arg-1] == "":
+			types[plural.arg-1] = "int"
+		}
+	}
+
+	params := make([]accessorParam, len(types))
+
+	for idx, typ := range types {
+		name := "arg" + strconv.Itoa(idx+1)
+		if idx < len(t.Placeholders) {
+			name = t.Placeholders[idx]
+			if token.IsKeyword(name) || name == "ctx" || name == "e" {
+				name += "Arg"
+			}
+		}
+
+		if typ == "" {
+			typ = "interface{}"
+		}
+
+		params[idx] = accessorParam{name: name, typ: typ}
+	}
+
+	return params
+}
+
+func verbType(verb string) string {
+	switch verb[len(verb)-1] {
+	case 'd', 'b', 'o', 'O', 'c', 'U':
+		return "int"
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return "float64"
+	case 's', 'q':
+		return "string"
+	case 't':
+		return "bool"
+	default:
+		return "interface{}"
+	}
+}
+
+// goName turns the key into an exported identifier, e.g. "test plural" into TestPlural. Only ASCII letters and
+// digits are kept, other characters separate words. Empty is returned if nothing is kept.
+func goName(key string) string {
+	var name strings.Builder
+
+	upper := true
+
+	for _, r := range key {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		name.WriteRune(r)
+	}
+
+	if name.Len() == 0 {
+		return ""
+	}
+
+	if !unicode.IsUpper(rune(name.String()[0])) {
+		return "Msg" + name.String()
+	}
+
+	return name.String()
+}
@@ -0,0 +1,108 @@
+package internal_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestGenerateAccessors(t *testing.T) {
+	t.Parallel()
+
+	locales := []Locale{
+		{Language: "ru", Messages: []Message{{Key: "ru only", Translation: "Только русский"}}},
+		{Language: "en", Messages: []Message{
+			{Key: "$unit", Translation: "item"},
+			{Key: "transition not allowed", Placeholders: []string{"from", "to"}, Translation: "Transition from {from:s} to {to:s}"},
+			{Key: "Items", Plural: map[string]string{"=0": "no items", "other": "%d items"}},
+			{Key: "Weight", Digits: digits(1), Plural: map[string]string{"other": "%.1f kilograms"}},
+			{Key: "Mixed", Translation: "%s has %d items, %v"},
+			{Key: "Type", Placeholders: []string{"type"}, Translation: "{type}"},
+			{Key: "Files", Placeholders: []string{"owner", "count"}, Plural: map[string]string{"other": "{owner:s} has {count} files"}},
+		}},
+	}
+
+	t.Run("messages", func(t *testing.T) {
+		t.Parallel()
+
+		var src bytes.Buffer
+
+		require.NoError(t, GenerateAccessors(&src, AccessorOptions{Package: "msgs", Language: language.English}, locales))
+
+		_, err := parser.ParseFile(token.NewFileSet(), "msgs_gen.go", src.Bytes(), 0)
+		require.NoError(t, err)
+
+		assert.Contains(t, src.String(), `= "transition not allowed"`)
+		assert.Contains(t, src.String(), "func TransitionNotAllowed(ctx context.Context, from, to string) string")
+		assert.Contains(t, src.String(), "func Items(ctx context.Context, arg1 int) string")
+		assert.Contains(t, src.String(), "func Weight(ctx context.Context, arg1 float64) string")
+		assert.Contains(t, src.String(), "func Mixed(ctx context.Context, arg1 string, arg2 int, arg3 interface{}) string")
+		assert.Contains(t, src.String(), "func Type(ctx context.Context, typeArg interface{}) string")
+		assert.Contains(t, src.String(), "func Files(ctx context.Context, owner string, count int) string")
+		assert.NotContains(t, src.String(), "Unit")
+		assert.NotContains(t, src.String(), "RuOnly")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		var src bytes.Buffer
+
+		require.NoError(t, GenerateAccessors(&src, AccessorOptions{Package: "errs", Language: language.English, Errors: true}, locales))
+
+		_, err := parser.ParseFile(token.NewFileSet(), "errs_gen.go", src.Bytes(), 0)
+		require.NoError(t, err)
+
+		assert.Contains(t, src.String(), "var TransitionNotAllowed = TransitionNotAllowedError{err: i18n.NewError(KeyTransitionNotAllowed)}")
+		assert.Contains(t, src.String(), "func (e TransitionNotAllowedError) WithParams(from, to string) *i18n.Error")
+	})
+
+	t.Run("no reference language", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Error(t, GenerateAccessors(&bytes.Buffer{}, AccessorOptions{Package: "msgs", Language: language.German}, locales))
+	})
+
+	t.Run("name collision", func(t *testing.T) {
+		t.Parallel()
+
+		err := GenerateAccessors(&bytes.Buffer{}, AccessorOptions{Package: "msgs", Language: language.English}, []Locale{
+			{Language: "en", Messages: []Message{{Key: "not found", Translation: "a"}, {Key: "not_found", Translation: "b"}}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `keys "not found" and "not_found" both generate identifier NotFound`)
+
+		err = GenerateAccessors(&bytes.Buffer{}, AccessorOptions{Package: "msgs", Language: language.English, Errors: true}, []Locale{
+			{Language: "en", Messages: []Message{{Key: "user", Translation: "a"}, {Key: "user error", Translation: "b"}}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "UserError")
+	})
+
+	t.Run("non-ASCII keys", func(t *testing.T) {
+		t.Parallel()
+
+		var src bytes.Buffer
+
+		require.NoError(t, GenerateAccessors(&src, AccessorOptions{Package: "msgs", Language: language.English}, []Locale{
+			{Language: "en", Messages: []Message{{Key: "café menu", Translation: "Menu"}}},
+		}))
+		assert.Contains(t, src.String(), "func CafMenu(ctx context.Context) string")
+
+		err := GenerateAccessors(&bytes.Buffer{}, AccessorOptions{Package: "msgs", Language: language.English}, []Locale{
+			{Language: "en", Messages: []Message{{Key: "привет", Translation: "Hi"}}},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func digits(n int) *int {
+	return &n
+}
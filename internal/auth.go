@@ -0,0 +1,253 @@
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RequestDecorator modifies every request made by ExternalLoader, e.g. to authenticate it.
+type RequestDecorator interface {
+	Decorate(req *http.Request) error
+}
+
+type RequestDecoratorFunc func(req *http.Request) error
+
+func (f RequestDecoratorFunc) Decorate(req *http.Request) error {
+	return f(req)
+}
+
+// WithRequestDecorators sets decorators applied in order to every request attempt.
+func (e *ExternalLoader) WithRequestDecorators(decorators ...RequestDecorator) *ExternalLoader {
+	e.decorators = decorators
+
+	return e
+}
+
+func (e *ExternalLoader) decorate(req *http.Request) error {
+	for _, decorator := range e.decorators {
+		if err := decorator.Decorate(req); err != nil {
+			return errors.Wrap(err, "decorate request")
+		}
+	}
+
+	return nil
+}
+
+// CredentialsInvalidator is implemented by decorators with cached credentials. If a request is rejected
+// with 401 Unauthorized, credentials it was decorated with are invalidated and the request is repeated once.
+type CredentialsInvalidator interface {
+	// Invalidate drops cached credentials the request was decorated with, false is returned if there were none.
+	Invalidate(req *http.Request) bool
+}
+
+// invalidate invalidates credentials of the rejected request, reports whether the request should be repeated.
+func (e *ExternalLoader) invalidate(req *http.Request) bool {
+	var invalidated bool
+
+	for _, decorator := range e.decorators {
+		if invalidator, ok := decorator.(CredentialsInvalidator); ok && invalidator.Invalidate(req) {
+			invalidated = true
+		}
+	}
+
+	return invalidated
+}
+
+// tokenExpiryMargin is subtracted from the token lifetime to refresh it before it expires, at most half
+// of the lifetime is subtracted.
+const tokenExpiryMargin = 10 * time.Second
+
+// ClientCredentials authenticates requests with OAuth2 client credentials grant tokens.
+type ClientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+
+	mu         sync.Mutex
+	token      string
+	expiry     time.Time
+	refreshing chan struct{}
+}
+
+func NewClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) *ClientCredentials {
+	return &ClientCredentials{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+		client:       &http.Client{Timeout: defaultRequestTimeout},
+	}
+}
+
+func (c *ClientCredentials) WithClient(client *http.Client) *ClientCredentials {
+	c.client = client
+
+	return c
+}
+
+func (c *ClientCredentials) Decorate(req *http.Request) error {
+	token, err := c.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
+
+// Invalidate drops the cached token if the request was decorated with it, e.g. when the token was revoked.
+func (c *ClientCredentials) Invalidate(req *http.Request) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return false
+	}
+
+	if token == c.token {
+		c.token, c.expiry = "", time.Time{}
+	}
+
+	return true
+}
+
+// Token returns cached token or requests a new one if it is about to expire. Concurrent callers wait for
+// a single token request.
+func (c *ClientCredentials) Token(ctx context.Context) (string, error) {
+	for {
+		c.mu.Lock()
+
+		if c.token != "" && (c.expiry.IsZero() || time.Now().Before(c.expiry)) {
+			token := c.token
+			c.mu.Unlock()
+
+			return token, nil
+		}
+
+		if wait := c.refreshing; wait != nil {
+			c.mu.Unlock()
+
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return "", errors.Wrap(ctx.Err(), "wait for token")
+			}
+		}
+
+		done := make(chan struct{})
+		c.refreshing = done
+		c.mu.Unlock()
+
+		token, expiry, err := c.requestToken(ctx)
+
+		c.mu.Lock()
+		if err == nil {
+			c.token, c.expiry = token, expiry
+		}
+
+		c.refreshing = nil
+		close(done)
+		c.mu.Unlock()
+
+		return token, err
+	}
+}
+
+// requestToken requests a new token and returns it with the time it should be refreshed at.
+func (c *ClientCredentials) requestToken(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{"grant_type": []string{"client_credentials"}}
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "new token request")
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	response, err := c.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "do token request")
+	}
+
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return "", time.Time{}, errors.Wrapf(ErrInvalidResponseCode, "token endpoint status %d", response.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", time.Time{}, errors.Wrap(err, "decode token")
+	}
+
+	if token.AccessToken == "" {
+		return "", time.Time{}, errors.New("empty access token")
+	}
+
+	var expiry time.Time
+
+	if token.ExpiresIn > 0 {
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+
+		margin := tokenExpiryMargin
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+
+		expiry = time.Now().Add(lifetime - margin)
+	}
+
+	return token.AccessToken, expiry, nil
+}
+
+// HMACSigner signs requests with HMAC-SHA256 of the method, request URI and date.
+type HMACSigner struct {
+	keyID  string
+	secret []byte
+	now    func() time.Time
+}
+
+func NewHMACSigner(keyID string, secret []byte) *HMACSigner {
+	return &HMACSigner{keyID: keyID, secret: secret, now: time.Now}
+}
+
+func (s *HMACSigner) Decorate(req *http.Request) error {
+	date := s.now().UTC().Format(http.TimeFormat)
+	req.Header.Set("Date", date)
+
+	req.Header.Set("Authorization", "HMAC-SHA256 keyId="+s.keyID+",signature="+s.Sign(req.Method, req.URL.RequestURI(), date))
+
+	return nil
+}
+
+func (s *HMACSigner) Sign(method, requestURI, date string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(method + "\n" + requestURI + "\n" + date))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,161 @@
+package internal_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestExternalLoader_Decorators(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Published"}]`)},
+		"locales/ru/active.json": &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Опубликовано"}]`)},
+	}
+
+	builder, err := InitBuilder(context.Background(), files)
+	require.NoError(t, err)
+
+	t.Run("client credentials", func(t *testing.T) {
+		t.Parallel()
+
+		var tokenRequests int32
+
+		tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&tokenRequests, 1)
+
+			user, pass, _ := r.BasicAuth()
+			if user != "client" || pass != "secret" || r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "i18n" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"access_token": "token-1", "token_type": "bearer", "expires_in": 3600}`))
+		}))
+		defer tokenSrv.Close()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token-1" || r.Header.Get("X-Static") != "1" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`[]`))
+		}))
+		defer srv.Close()
+
+		header := http.Header{"X-Static": []string{"1"}}
+		loader := NewExternalLoader(srv.URL, header).
+			WithParallelism(2).
+			WithRequestDecorators(NewClientCredentials(tokenSrv.URL, "client", "secret", "i18n"))
+
+		require.NoError(t, loader.Load(context.Background(), builder))
+		require.NoError(t, loader.Load(context.Background(), builder))
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&tokenRequests))
+		assert.Equal(t, http.Header{"X-Static": []string{"1"}}, header)
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		t.Parallel()
+
+		var tokenRequests int32
+
+		tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&tokenRequests, 1)
+
+			_, _ = fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": 3600}`, n)
+		}))
+		defer tokenSrv.Close()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`[]`))
+		}))
+		defer srv.Close()
+
+		loader := NewExternalLoader(srv.URL, nil).WithRequestDecorators(NewClientCredentials(tokenSrv.URL, "client", "secret"))
+
+		require.NoError(t, loader.Load(context.Background(), builder))
+		require.NoError(t, loader.Load(context.Background(), builder))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&tokenRequests))
+	})
+
+	t.Run("short lived token", func(t *testing.T) {
+		t.Parallel()
+
+		var tokenRequests int32
+
+		tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&tokenRequests, 1)
+
+			_, _ = w.Write([]byte(`{"access_token": "token", "expires_in": 4}`))
+		}))
+		defer tokenSrv.Close()
+
+		credentials := NewClientCredentials(tokenSrv.URL, "client", "secret")
+
+		for i := 0; i < 3; i++ {
+			token, err := credentials.Token(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "token", token)
+		}
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&tokenRequests))
+	})
+
+	t.Run("hmac", func(t *testing.T) {
+		t.Parallel()
+
+		signer := NewHMACSigner("key", []byte("secret"))
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			expected := "HMAC-SHA256 keyId=key,signature=" + signer.Sign(r.Method, r.URL.RequestURI(), r.Header.Get("Date"))
+			if r.Header.Get("Authorization") != expected {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`[]`))
+		}))
+		defer srv.Close()
+
+		require.NoError(t, NewExternalLoader(srv.URL, nil).WithRequestDecorators(signer).Load(context.Background(), builder))
+	})
+
+	t.Run("decorator error", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[]`))
+		}))
+		defer srv.Close()
+
+		err := NewExternalLoader(srv.URL, nil).
+			WithRequestDecorators(RequestDecoratorFunc(func(req *http.Request) error {
+				return assert.AnError
+			})).
+			Load(context.Background(), builder)
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "decorate request"))
+	})
+}
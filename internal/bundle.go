@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"context"
+	"io/fs"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// bundleRank is below ranks of all application layers, so the application overrides messages of bundles.
+const bundleRank = math.MinInt32
+
+type namespaceKey struct{}
+
+// withNamespace makes applied keys prefixed with the namespace.
+func withNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+func namespaceFrom(ctx context.Context) string {
+	namespace, _ := ctx.Value(namespaceKey{}).(string)
+
+	return namespace
+}
+
+// bundle is a locales filesystem contributed by a library.
+type bundle struct {
+	namespace string
+	files     fs.ReadDirFS
+	patterns  []string
+}
+
+var (
+	bundlesMu sync.RWMutex
+	bundles   []bundle
+)
+
+// RegisterBundle adds locale files of a library to catalogs created after the call, usually it is called from
+// init. Keys of the bundle are prefixed with "<namespace>.", e.g. "required" of the validation bundle is
+// "validation.required", variables are shared with the application. Messages of the application override
+// messages of bundles. Patterns default to locales/{lang}/active.json. Panics if the namespace is empty or
+// already registered, or a pattern is invalid.
+func RegisterBundle(namespace string, files fs.ReadDirFS, patterns ...string) {
+	if namespace == "" {
+		panic("i18n: empty bundle namespace")
+	}
+
+	if len(patterns) == 0 {
+		patterns = defaultLayout
+	}
+
+	if err := validateLayout(patterns); err != nil {
+		panic("i18n: bundle " + namespace + ": " + err.Error())
+	}
+
+	bundlesMu.Lock()
+	defer bundlesMu.Unlock()
+
+	for _, item := range bundles {
+		if item.namespace == namespace {
+			panic("i18n: bundle " + namespace + " is already registered")
+		}
+	}
+
+	bundles = append(bundles, bundle{namespace: namespace, files: files, patterns: patterns})
+}
+
+// unregisterBundle removes the bundle, so tests may register bundles without affecting each other.
+func unregisterBundle(namespace string) {
+	bundlesMu.Lock()
+	defer bundlesMu.Unlock()
+
+	for idx, item := range bundles {
+		if item.namespace == namespace {
+			bundles = append(bundles[:idx:idx], bundles[idx+1:]...)
+
+			return
+		}
+	}
+}
+
+func registeredBundles() []bundle {
+	bundlesMu.RLock()
+	defer bundlesMu.RUnlock()
+
+	return bundles
+}
+
+// applyBundles applies registered bundles, only locales of the lang unless it is language.Und.
+func applyBundles(ctx context.Context, cat *catalog.Builder, lang language.Tag) error {
+	var errs LoadErrors
+
+	for _, item := range registeredBundles() {
+		locales, err := readLocales(item.files, item.patterns)
+		if err != nil {
+			return errors.WithMessagef(err, "read bundle %s", item.namespace)
+		}
+
+		if lang != language.Und {
+			filtered := locales[:0:0]
+
+			for _, locale := range locales {
+				if locale.lang == lang {
+					filtered = append(filtered, locale)
+				}
+			}
+
+			locales = filtered
+		}
+
+		bundleCtx := withNamespace(withLayer(ctx, layer{name: item.namespace, rank: bundleRank}), item.namespace)
+
+		if err := applyLocales(bundleCtx, locales, cat, &errs); err != nil {
+			return errors.WithMessagef(err, "apply bundle %s", item.namespace)
+		}
+	}
+
+	return errs.errOrNil()
+}
+
+// bundleLanguages returns languages of registered bundles.
+func bundleLanguages() ([]language.Tag, error) {
+	var languages []language.Tag
+
+	for _, item := range registeredBundles() {
+		found, err := findLayout(item.files, item.patterns)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "index bundle %s", item.namespace)
+		}
+
+		for _, locale := range found {
+			lang, err := language.Parse(locale.name)
+			if err != nil {
+				continue
+			}
+
+			languages = append(languages, lang)
+		}
+	}
+
+	return languages, nil
+}
+
+// namespaced returns translations with keys prefixed by the namespace.
+func namespaced(translations []Translation, namespace string) []Translation {
+	prefixed := make([]Translation, len(translations))
+
+	for idx := range translations {
+		prefixed[idx] = translations[idx]
+
+		if !prefixed[idx].isVar() {
+			prefixed[idx].Key = namespace + "." + prefixed[idx].Key
+		}
+	}
+
+	return prefixed
+}
@@ -0,0 +1,66 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+// Bundles are global, so the test is not parallel and unregisters the bundle when it is done.
+func TestRegisterBundle(t *testing.T) {
+	RegisterBundle("billing", fstest.MapFS{
+		"i18n/en.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Invoice", "placeholders": ["number"], "translation": "Invoice #{number}"},
+  {"key": "Paid", "translation": "Paid"}
+]`)},
+	}, "i18n/{lang}.json")
+	t.Cleanup(func() { UnregisterBundle("billing") })
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "Paid", "translation": "Application paid"},
+  {"key": "billing.Paid", "translation": "Settled"}
+]`))
+	require.NoError(t, err)
+
+	printer := message.NewPrinter(language.English, message.Catalog(builder))
+
+	args := NamedArgs(builder, "billing.Invoice", map[string]int{"number": 42})
+	assert.Equal(t, "Invoice #42", printer.Sprintf("billing.Invoice", args...))
+	assert.Equal(t, "Settled", printer.Sprintf("billing.Paid"))
+	assert.Equal(t, "Application paid", printer.Sprintf("Paid"))
+
+	source, ok := MessageSource(builder, language.English, "billing.Invoice")
+	require.True(t, ok)
+	assert.Equal(t, "billing", source)
+
+	t.Run("lazy", func(t *testing.T) {
+		t.Parallel()
+
+		lazy, err := InitLazy(context.Background(), LazyOptions{}, localeFS(`[{"key": "billing.Paid", "translation": "Settled"}]`))
+		require.NoError(t, err)
+
+		printer := message.NewPrinter(language.English, message.Catalog(lazy.Builder(context.Background(), language.English)))
+
+		assert.Equal(t, "Invoice #42", printer.Sprintf("billing.Invoice", NamedArgs(lazy.Root(), "billing.Invoice", map[string]int{"number": 42})...))
+		assert.Equal(t, "Settled", printer.Sprintf("billing.Paid"))
+	})
+
+	t.Run("duplicate namespace", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Panics(t, func() { RegisterBundle("billing", fstest.MapFS{}) })
+	})
+
+	t.Run("invalid layout", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Panics(t, func() { RegisterBundle("auth", fstest.MapFS{}, "i18n/active.json") })
+	})
+}
@@ -0,0 +1,337 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+var ErrCacheStale = errors.New("cached translations are stale")
+
+type CachePolicy uint8
+
+const (
+	// CacheFail fails the load if remote is unreachable, cache is only written.
+	CacheFail CachePolicy = iota
+	// CacheUse applies the last cached bundle if remote is unreachable. With signatures or a checksum manifest the
+	// cached bundle is verified again by the signature or the manifest stored along with it.
+	CacheUse
+	// CacheEmbeddedOnly ignores remote failures and keeps already loaded translations.
+	CacheEmbeddedOnly
+)
+
+type CacheOptions struct {
+	Dir      string
+	Policy   CachePolicy
+	MaxStale time.Duration
+}
+
+// cacheMeta describes a cached bundle.
+type cacheMeta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	ValidatedAt  time.Time `json:"validated_at"`
+	// Proof is the signature or the signed manifest the bundle was verified by, nil without verification.
+	Proof *bundleProof `json:"proof,omitempty"`
+}
+
+// WithCache enables persisting of fetched bundles to the directory and the fallback policy.
+func (e *ExternalLoader) WithCache(options CacheOptions) *ExternalLoader {
+	e.cache = &options
+
+	return e
+}
+
+func (e *ExternalLoader) cachePaths(lang language.Tag) (string, string) {
+	base := filepath.Join(e.cache.Dir, lang.String())
+
+	return base + ".json", base + ".meta.json"
+}
+
+func (e *ExternalLoader) storeCache(lang language.Tag, langURL string, response *fetched, proof *bundleProof) error {
+	if e.cache == nil || e.cache.Dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(e.cache.Dir, 0o755); err != nil {
+		return errors.Wrap(err, "create cache dir")
+	}
+
+	bundlePath, metaPath := e.cachePaths(lang)
+
+	if err := writeFileAtomic(bundlePath, response.body); err != nil {
+		return errors.WithMessage(err, "write bundle")
+	}
+
+	now := time.Now()
+
+	return e.writeCacheMeta(metaPath, cacheMeta{
+		URL:          langURL,
+		ETag:         response.header.Get("ETag"),
+		LastModified: response.header.Get("Last-Modified"),
+		FetchedAt:    now,
+		ValidatedAt:  now,
+		Proof:        proof,
+	})
+}
+
+// touchCache marks cached bundle as still valid after 304 Not Modified.
+func (e *ExternalLoader) touchCache(lang language.Tag) error {
+	if e.cache == nil || e.cache.Dir == "" {
+		return nil
+	}
+
+	_, metaPath := e.cachePaths(lang)
+
+	meta, err := readCacheMeta(metaPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	meta.ValidatedAt = time.Now()
+
+	return e.writeCacheMeta(metaPath, meta)
+}
+
+func (e *ExternalLoader) writeCacheMeta(metaPath string, meta cacheMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return errors.Wrap(err, "marshal cache meta")
+	}
+
+	if err := writeFileAtomic(metaPath, data); err != nil {
+		return errors.WithMessage(err, "write cache meta")
+	}
+
+	return nil
+}
+
+// transportError is a failure to get any response from the remote.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// remoteUnavailable reports whether err means the remote could not serve the request: transport failures and
+// 5xx responses. Other failures, e.g. malformed or rejected bundles, are not tolerated by the cache policy.
+func remoteUnavailable(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var transportErr *transportError
+
+	return errors.As(err, &transportErr)
+}
+
+// unreachable handles remote failure which happened before any language was loaded, e.g. of the discovery index
+// or the checksum manifest. With CacheUse every cached language is restored.
+func (e *ExternalLoader) unreachable(ctx context.Context, builder *catalog.Builder, err error) (bool, error) {
+	if e.cache == nil || e.cache.Policy == CacheFail || !remoteUnavailable(err) {
+		return false, err
+	}
+
+	var languages []language.Tag
+
+	if e.cache.Policy == CacheUse {
+		cached, cacheErr := e.cachedLanguages()
+		if cacheErr != nil {
+			return false, errors.WithMessagef(err, "cache fallback failed: %s", cacheErr.Error())
+		}
+
+		languages = cached
+
+		if only, ok := languagesFrom(ctx); ok {
+			languages = intersectLanguages(cached, only)
+		}
+	}
+
+	var (
+		stats    = make([]LanguageStat, len(languages))
+		firstErr *LanguageStat
+	)
+
+	for idx, lang := range languages {
+		stats[idx] = LanguageStat{Language: lang, Err: err}
+		e.fallback(ctx, lang, builder, &stats[idx])
+
+		if stats[idx].Err != nil && firstErr == nil {
+			firstErr = &stats[idx]
+		}
+	}
+
+	return e.finish(stats, firstErr)
+}
+
+// cachedLanguages returns languages having a bundle in the cache dir.
+func (e *ExternalLoader) cachedLanguages() ([]language.Tag, error) {
+	if e.cache.Dir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(e.cache.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "read cache dir")
+	}
+
+	var languages []language.Tag
+
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".meta.json")
+		if name == entry.Name() || entry.IsDir() {
+			continue
+		}
+
+		if lang, err := language.Parse(name); err == nil {
+			languages = append(languages, lang)
+		}
+	}
+
+	return languages, nil
+}
+
+func intersectLanguages(languages, only []language.Tag) []language.Tag {
+	var result []language.Tag
+
+	for _, lang := range languages {
+		for _, allowed := range only {
+			if lang == allowed {
+				result = append(result, lang)
+
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// fallback handles remote failure of the language according to the cache policy. Only unavailability of the
+// remote is tolerated.
+func (e *ExternalLoader) fallback(ctx context.Context, lang language.Tag, builder *catalog.Builder, stat *LanguageStat) {
+	if e.cache == nil || !remoteUnavailable(stat.Err) {
+		return
+	}
+
+	switch e.cache.Policy {
+	case CacheFail:
+		return
+
+	case CacheEmbeddedOnly:
+		stat.RemoteErr, stat.Err = stat.Err, nil
+
+	case CacheUse:
+		meta, rejected, err := e.loadCache(ctx, lang, builder)
+		if err != nil {
+			stat.Err = errors.WithMessagef(stat.Err, "cache fallback failed: %s", err.Error())
+
+			return
+		}
+
+		stat.RemoteErr, stat.Err = stat.Err, nil
+		stat.FromCache = true
+		stat.Rejected = rejected
+		stat.CachedAt = meta.FetchedAt
+		stat.Changed = true
+	}
+}
+
+func (e *ExternalLoader) loadCache(ctx context.Context, lang language.Tag, builder *catalog.Builder) (cacheMeta, []RejectedMessage, error) {
+	bundlePath, metaPath := e.cachePaths(lang)
+
+	meta, err := readCacheMeta(metaPath)
+	if err != nil {
+		return meta, nil, err
+	}
+
+	if e.cache.MaxStale > 0 && time.Since(meta.ValidatedAt) > e.cache.MaxStale {
+		return meta, nil, errors.Wrapf(ErrCacheStale, "validated at %s", meta.ValidatedAt.Format(time.RFC3339))
+	}
+
+	data, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return meta, nil, errors.Wrap(err, "read cached bundle")
+	}
+
+	if e.verifier != nil {
+		if err := e.verifier.verifyCached(lang, data, meta.Proof); err != nil {
+			return meta, nil, errors.WithMessage(err, "verify cached bundle")
+		}
+	}
+
+	rejected, err := e.apply(ctx, data, lang, builder)
+	if err != nil {
+		return meta, nil, errors.WithMessage(err, "apply cached bundle")
+	}
+
+	return meta, rejected, nil
+}
+
+func readCacheMeta(metaPath string) (cacheMeta, error) {
+	var meta cacheMeta
+
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return meta, errors.Wrap(err, "read cache meta")
+	}
+
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, errors.Wrap(err, "decode cache meta")
+	}
+
+	return meta, nil
+}
+
+func writeFileAtomic(filePath string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "create temp file")
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return errors.Wrap(err, "write temp file")
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return errors.Wrap(err, "close temp file")
+	}
+
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return errors.Wrap(err, "rename temp file")
+	}
+
+	return nil
+}
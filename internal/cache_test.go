@@ -0,0 +1,314 @@
+package internal_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestExternalLoader_Cache(t *testing.T) {
+	t.Parallel()
+
+	var down int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Remote"}]`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	newBuilder := func(t *testing.T) *catalog.Builder {
+		builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+		require.NoError(t, err)
+
+		return builder
+	}
+
+	translate := func(builder *catalog.Builder) string {
+		return message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published")
+	}
+
+	require.NoError(t, NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: dir}).Load(context.Background(), newBuilder(t)))
+
+	atomic.StoreInt32(&down, 1)
+
+	t.Run("use cache", func(t *testing.T) {
+		builder := newBuilder(t)
+		loader := NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: dir, Policy: CacheUse, MaxStale: time.Hour})
+
+		require.NoError(t, loader.Load(context.Background(), builder))
+		assert.Equal(t, "Remote", translate(builder))
+
+		stats := loader.LastStats()
+		require.Len(t, stats, 1)
+		assert.True(t, stats[0].FromCache)
+		assert.Error(t, stats[0].RemoteErr)
+		assert.WithinDuration(t, time.Now(), stats[0].CachedAt, time.Minute)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		builder := newBuilder(t)
+
+		require.Error(t, NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: dir}).Load(context.Background(), builder))
+		assert.Equal(t, "Embedded", translate(builder))
+	})
+
+	t.Run("embedded only", func(t *testing.T) {
+		builder := newBuilder(t)
+
+		require.NoError(t, NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Policy: CacheEmbeddedOnly}).Load(context.Background(), builder))
+		assert.Equal(t, "Embedded", translate(builder))
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		builder := newBuilder(t)
+		loader := NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: dir, Policy: CacheUse, MaxStale: time.Nanosecond})
+
+		err := loader.Load(context.Background(), builder)
+		require.Error(t, err)
+		assert.Equal(t, "Embedded", translate(builder))
+	})
+
+	t.Run("missing cache", func(t *testing.T) {
+		err := NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: t.TempDir(), Policy: CacheUse}).Load(context.Background(), newBuilder(t))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidResponseCode))
+	})
+}
+
+func TestExternalLoader_CacheDiscoveryDown(t *testing.T) {
+	t.Parallel()
+
+	var down int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case atomic.LoadInt32(&down) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/index":
+			_, _ = w.Write([]byte(`["en", "de"]`))
+		case r.URL.Path == "/de":
+			_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Veröffentlicht"}]`))
+		default:
+			_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Remote"}]`))
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	newBuilder := func(t *testing.T) *catalog.Builder {
+		builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+		require.NoError(t, err)
+
+		return builder
+	}
+
+	newLoader := func(policy CachePolicy) *ExternalLoader {
+		return NewExternalLoader(srv.URL, nil).WithDiscovery("index").WithCache(CacheOptions{Dir: dir, Policy: policy})
+	}
+
+	require.NoError(t, newLoader(CacheFail).Load(context.Background(), newBuilder(t)))
+
+	atomic.StoreInt32(&down, 1)
+
+	t.Run("use cache", func(t *testing.T) {
+		builder := newBuilder(t)
+		loader := newLoader(CacheUse)
+
+		require.NoError(t, loader.Load(context.Background(), builder))
+		assert.Equal(t, "Remote", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+		assert.Equal(t, "Veröffentlicht", message.NewPrinter(language.German, message.Catalog(builder)).Sprintf("Published"))
+
+		stats := loader.LastStats()
+		require.Len(t, stats, 2)
+
+		for _, stat := range stats {
+			assert.True(t, stat.FromCache)
+			assert.Error(t, stat.RemoteErr)
+		}
+	})
+
+	t.Run("embedded only", func(t *testing.T) {
+		builder := newBuilder(t)
+
+		require.NoError(t, newLoader(CacheEmbeddedOnly).Load(context.Background(), builder))
+		assert.Equal(t, "Embedded", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		err := newLoader(CacheFail).Load(context.Background(), newBuilder(t))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidResponseCode))
+	})
+}
+
+func TestExternalLoader_CacheMalformedBundle(t *testing.T) {
+	t.Parallel()
+
+	var broken int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&broken) == 1 {
+			_, _ = w.Write([]byte(`[{"key": `))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Remote"}]`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+	require.NoError(t, err)
+
+	require.NoError(t, NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: dir}).Load(context.Background(), builder))
+
+	atomic.StoreInt32(&broken, 1)
+
+	loader := NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: dir, Policy: CacheUse})
+
+	require.Error(t, loader.Load(context.Background(), builder))
+	assert.False(t, loader.LastStats()[0].FromCache)
+}
+
+func TestExternalLoader_CacheVerified(t *testing.T) {
+	t.Parallel()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	bundle := []byte(`[{"key": "Published", "translation": "Remote"}]`)
+	sum := sha256.Sum256(bundle)
+	manifest := []byte(`{"en": "` + hex.EncodeToString(sum[:]) + `"}`)
+
+	sign := func(subject string, data []byte) []byte {
+		signature, err := SignBundle(private, subject, 1, data)
+		require.NoError(t, err)
+
+		return signature
+	}
+
+	var down int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/en":
+			_, _ = w.Write(bundle)
+		case "/en.sig":
+			_, _ = w.Write(sign("en", bundle))
+		case "/manifest.json":
+			_, _ = w.Write(manifest)
+		case "/manifest.json.sig":
+			_, _ = w.Write(sign("manifest", manifest))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	signedDir, manifestDir := t.TempDir(), t.TempDir()
+
+	newBuilder := func(t *testing.T) *catalog.Builder {
+		builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+		require.NoError(t, err)
+
+		return builder
+	}
+
+	translate := func(builder *catalog.Builder) string {
+		return message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published")
+	}
+
+	signed := func(dir string) *ExternalLoader {
+		return NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: dir, Policy: CacheUse}).WithSignatures(public)
+	}
+
+	withManifest := func(dir string) *ExternalLoader {
+		return NewExternalLoader(srv.URL, nil).WithCache(CacheOptions{Dir: dir, Policy: CacheUse}).WithChecksumManifest("manifest.json", public)
+	}
+
+	require.NoError(t, signed(signedDir).Load(context.Background(), newBuilder(t)))
+	require.NoError(t, withManifest(manifestDir).Load(context.Background(), newBuilder(t)))
+
+	atomic.StoreInt32(&down, 1)
+
+	t.Run("signature", func(t *testing.T) {
+		builder := newBuilder(t)
+
+		require.NoError(t, signed(signedDir).Load(context.Background(), builder))
+		assert.Equal(t, "Remote", translate(builder))
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		builder := newBuilder(t)
+
+		require.NoError(t, withManifest(manifestDir).Load(context.Background(), builder))
+		assert.Equal(t, "Remote", translate(builder))
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := []byte(`[{"key": "Published", "translation": "Injected"}]`)
+		require.NoError(t, os.WriteFile(filepath.Join(signedDir, "en.json"), tampered, 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "en.json"), tampered, 0o600))
+
+		builder := newBuilder(t)
+
+		// The cache error is reported along with the remote one.
+		err := signed(signedDir).Load(context.Background(), builder)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ErrInvalidSignature.Error())
+		assert.Equal(t, "Embedded", translate(builder))
+
+		err = withManifest(manifestDir).Load(context.Background(), builder)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ErrChecksumMismatch.Error())
+		assert.Equal(t, "Embedded", translate(builder))
+	})
+
+	t.Run("unverified cache", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "en.json"), bundle, 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "en.meta.json"), []byte(`{"fetched_at": "2024-01-01T00:00:00Z"}`), 0o600))
+
+		builder := newBuilder(t)
+
+		err := signed(dir).Load(context.Background(), builder)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ErrInvalidSignature.Error())
+		assert.Equal(t, "Embedded", translate(builder))
+	})
+}
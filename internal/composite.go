@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+type layerKey struct{}
+
+// layer identifies the source of messages, messages of higher rank are not overridden by lower ones.
+type layer struct {
+	name string
+	rank int
+	// sub are ranks of nested CompositeLoader layers, compared after rank.
+	sub []int
+}
+
+// above reports whether messages of the layer take precedence over messages of other.
+func (l layer) above(other layer) bool {
+	if l.rank != other.rank {
+		return l.rank > other.rank
+	}
+
+	for idx := 0; idx < len(l.sub) && idx < len(other.sub); idx++ {
+		if l.sub[idx] != other.sub[idx] {
+			return l.sub[idx] > other.sub[idx]
+		}
+	}
+
+	return len(l.sub) > len(other.sub)
+}
+
+// nested returns the idx-th layer of a CompositeLoader loaded within the layer.
+func (l layer) nested(name string, idx int) layer {
+	sub := make([]int, len(l.sub), len(l.sub)+1)
+	copy(sub, l.sub)
+
+	return layer{name: name, rank: l.rank, sub: append(sub, idx)}
+}
+
+func withLayer(ctx context.Context, source layer) context.Context {
+	return context.WithValue(ctx, layerKey{}, source)
+}
+
+func layerFrom(ctx context.Context) layer {
+	source, _ := ctx.Value(layerKey{}).(layer)
+
+	return source
+}
+
+// Layer is a named source of CompositeLoader.
+type Layer struct {
+	Name   string
+	Loader Loader
+}
+
+// CompositeLoader loads layers in order. Messages of later layers take precedence over earlier ones and over
+// messages loaded outside the composite, regardless of the order of subsequent reloads. A composite used as
+// a layer of another composite orders its layers within that layer. Precedence is only known to loaders of
+// this package, messages set to the catalog directly, e.g. by loaders wrapped with LoaderWithoutContext, are
+// not tracked and may be overwritten by any layer.
+type CompositeLoader struct {
+	layers   []Layer
+	tolerant bool
+}
+
+func NewCompositeLoader(layers ...Layer) *CompositeLoader {
+	return &CompositeLoader{layers: layers}
+}
+
+// WithContinueOnError makes loader continue past failing layers, failures are returned as LoadErrors.
+func (c *CompositeLoader) WithContinueOnError(enabled bool) *CompositeLoader {
+	c.tolerant = enabled
+
+	return c
+}
+
+func (c *CompositeLoader) Load(ctx context.Context, cat *catalog.Builder) error {
+	_, err := c.LoadChanged(ctx, cat)
+
+	return err
+}
+
+// LoadChanged loads all layers and reports whether any of them changed translations. Loaders which are not
+// ChangeLoader are considered always changing.
+func (c *CompositeLoader) LoadChanged(ctx context.Context, cat *catalog.Builder) (bool, error) {
+	var (
+		changed bool
+		errs    LoadErrors
+	)
+
+	parent := layerFrom(ctx)
+
+	for idx, item := range c.layers {
+		layerCtx := withLayer(ctx, parent.nested(item.Name, idx+1))
+
+		layerChanged, err := loadChanged(layerCtx, item.Loader, cat)
+		changed = changed || layerChanged
+
+		switch {
+		case err == nil:
+		case IsPartial(err):
+			errs.add(err)
+		case c.tolerant:
+			errs.Errors = append(errs.Errors, &LoadError{Language: language.Und, Source: item.Name, Err: err})
+		default:
+			return changed, errors.WithMessagef(err, "load layer %s", item.Name)
+		}
+	}
+
+	return changed, errs.errOrNil()
+}
+
+// discoverLanguages returns languages discovered by layers.
+func (c *CompositeLoader) discoverLanguages(ctx context.Context) ([]language.Tag, error) {
+	var languages []language.Tag
+
+	for _, item := range c.layers {
+		discoverer, ok := item.Loader.(languageDiscoverer)
+		if !ok {
+			continue
+		}
+
+		discovered, err := discoverer.discoverLanguages(ctx)
+		if err != nil {
+			if c.tolerant {
+				continue
+			}
+
+			return nil, errors.WithMessagef(err, "discover languages of layer %s", item.Name)
+		}
+
+		languages = mergeLanguages(languages, discovered)
+	}
+
+	return languages, nil
+}
+
+func loadChanged(ctx context.Context, loader Loader, cat *catalog.Builder) (bool, error) {
+	if loader, ok := loader.(ChangeLoader); ok {
+		return loader.LoadChanged(ctx, cat)
+	}
+
+	if err := loader.Load(ctx, cat); err != nil {
+		return IsPartial(err), err
+	}
+
+	return true, nil
+}
+
+// FSLoader loads locale files, the same layout as Init uses unless WithLayout is set.
+type FSLoader struct {
+	files    fs.ReadDirFS
+	patterns []string
+}
+
+func NewFSLoader(files fs.ReadDirFS) *FSLoader {
+	return &FSLoader{files: files}
+}
+
+// WithLayout sets patterns of locale file paths, see SetLayout. Patterns are validated on load.
+func (f *FSLoader) WithLayout(patterns ...string) *FSLoader {
+	f.patterns = patterns
+
+	return f
+}
+
+func (f *FSLoader) Load(ctx context.Context, cat *catalog.Builder) error {
+	patterns := f.patterns
+	if len(patterns) == 0 {
+		patterns = layout
+	}
+
+	if err := validateLayout(patterns); err != nil {
+		return err
+	}
+
+	return loadLocales(ctx, f.files, patterns, cat)
+}
+
+// MessageSource returns name of the CompositeLoader layer or namespace of the bundle which supplied the message,
+// empty for other messages. Variables are looked up by their $name keys.
+func MessageSource(builder *catalog.Builder, lang language.Tag, key string) (string, bool) {
+	reg, ok := lookupRegistry(builder)
+	if !ok {
+		return "", false
+	}
+
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	if name := strings.TrimPrefix(key, varPrefix); name != key {
+		source, ok := reg.varLayers[lang][name]
+
+		return source.name, ok
+	}
+
+	rec, ok := reg.records[lang][key]
+
+	return rec.layer.name, ok
+}
@@ -0,0 +1,129 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestCompositeLoader(t *testing.T) {
+	t.Parallel()
+
+	base := localeFS(`[
+  {"key": "$brand", "translation": "Acme"},
+  {"key": "Published", "translation": "Base"},
+  {"key": "Title", "translation": "${brand} title"}
+]`)
+	override := localeFS(`[
+  {"key": "$brand", "translation": "Initech"},
+  {"key": "Published", "translation": "Override"}
+]`)
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+	require.NoError(t, err)
+
+	loader := NewCompositeLoader(
+		Layer{Name: "base", Loader: NewFSLoader(base)},
+		Layer{Name: "override", Loader: NewFSLoader(override)},
+	)
+
+	changed, err := loader.LoadChanged(context.Background(), builder)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	printer := message.NewPrinter(language.English, message.Catalog(builder))
+	assert.Equal(t, "Override", printer.Sprintf("Published"))
+	assert.Equal(t, "Initech title", printer.Sprintf("Title"))
+
+	for key, want := range map[string]string{"Published": "override", "Title": "base", "$brand": "override"} {
+		source, ok := MessageSource(builder, language.English, key)
+		assert.True(t, ok, key)
+		assert.Equal(t, want, source, key)
+	}
+
+	_, ok := MessageSource(builder, language.English, "Missing")
+	assert.False(t, ok)
+
+	t.Run("lower layer reload", func(t *testing.T) {
+		require.NoError(t, NewFSLoader(base).Load(context.Background(), builder))
+		require.NoError(t, loader.Load(context.Background(), builder))
+
+		assert.Equal(t, "Override", printer.Sprintf("Published"))
+		assert.Equal(t, "Initech title", printer.Sprintf("Title"))
+	})
+}
+
+func TestCompositeLoader_Nested(t *testing.T) {
+	t.Parallel()
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+	require.NoError(t, err)
+
+	fsLayer := func(name, translation string) Layer {
+		return Layer{Name: name, Loader: NewFSLoader(localeFS(`[{"key": "Published", "translation": "` + translation + `"}]`))}
+	}
+
+	outer := NewCompositeLoader(
+		fsLayer("base", "Base"),
+		Layer{Name: "defaults", Loader: NewCompositeLoader(fsLayer("regional", "Regional"))},
+	)
+
+	require.NoError(t, outer.Load(context.Background(), builder))
+
+	// The first layer of the nested composite stays above the first layer of the outer one.
+	require.NoError(t, NewCompositeLoader(fsLayer("base", "Base")).Load(context.Background(), builder))
+
+	assert.Equal(t, "Regional", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+
+	source, ok := MessageSource(builder, language.English, "Published")
+	require.True(t, ok)
+	assert.Equal(t, "regional", source)
+}
+
+func TestCompositeLoader_Errors(t *testing.T) {
+	t.Parallel()
+
+	failing := LoaderFunc(func(context.Context, *catalog.Builder) error {
+		return errors.New("unavailable")
+	})
+
+	layers := []Layer{
+		{Name: "database", Loader: failing},
+		{Name: "disk", Loader: NewFSLoader(localeFS(`[{"key": "Published", "translation": "Disk"}]`))},
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		t.Parallel()
+
+		builder := catalog.NewBuilder()
+
+		err := NewCompositeLoader(layers...).Load(context.Background(), builder)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "load layer database")
+		assert.Empty(t, builder.Languages())
+	})
+
+	t.Run("tolerant", func(t *testing.T) {
+		t.Parallel()
+
+		builder := catalog.NewBuilder()
+
+		err := NewCompositeLoader(layers...).WithContinueOnError(true).Load(context.Background(), builder)
+		require.True(t, IsPartial(err))
+
+		var loadErrs *LoadErrors
+		require.True(t, errors.As(err, &loadErrs))
+		require.Len(t, loadErrs.Errors, 1)
+		assert.Equal(t, "database", loadErrs.Errors[0].Source)
+
+		assert.Equal(t, "Disk", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+	})
+}
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+	"golang.org/x/text/number"
+)
+
+// PrepareArgs converts the plural argument of the key in the language to a decimal, so fraction
+// digits are visible both for plural form selection and formatting. Printers do not call it, arguments
+// passed to message.Printer directly are formatted as is.
+func PrepareArgs(builder *catalog.Builder, lang language.Tag, key string, args []interface{}) []interface{} {
+	plurals := pluralsOf(builder, lang, key)
+	if len(plurals) == 0 {
+		return args
+	}
+
+	var prepared []interface{}
+
+	for _, plural := range plurals {
+		if plural.arg == 0 || plural.arg > len(args) {
+			continue
+		}
+
+		dec, ok := toDecimal(args[plural.arg-1], plural.digits)
+		if !ok {
+			continue
+		}
+
+		if prepared == nil {
+			prepared = make([]interface{}, len(args))
+			copy(prepared, args)
+		}
+
+		prepared[plural.arg-1] = dec
+	}
+
+	if prepared == nil {
+		return args
+	}
+
+	return prepared
+}
+
+// toDecimal converts the argument to a decimal with the digits. Without digits, strings and floats keep their
+// shortest fraction digits, so 5.0 selects the same form as 5, integers are left as is.
+func toDecimal(arg interface{}, digits *int) (interface{}, bool) {
+	var value float64
+
+	switch typed := arg.(type) {
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		if err != nil {
+			return nil, false
+		}
+
+		if digits == nil {
+			return newDecimal(parsed, fractionDigits(typed)), true
+		}
+
+		value = parsed
+	case float32:
+		if digits == nil {
+			return newDecimal(float64(typed), fractionDigits(strconv.FormatFloat(float64(typed), 'f', -1, 32))), true
+		}
+
+		value = float64(typed)
+	case float64:
+		if digits == nil {
+			return newDecimal(typed, fractionDigits(strconv.FormatFloat(typed, 'f', -1, 64))), true
+		}
+
+		value = typed
+	case int:
+		value = float64(typed)
+	case int8:
+		value = float64(typed)
+	case int16:
+		value = float64(typed)
+	case int32:
+		value = float64(typed)
+	case int64:
+		value = float64(typed)
+	case uint:
+		value = float64(typed)
+	case uint8:
+		value = float64(typed)
+	case uint16:
+		value = float64(typed)
+	case uint32:
+		value = float64(typed)
+	case uint64:
+		value = float64(typed)
+	default:
+		return nil, false
+	}
+
+	if digits == nil {
+		return nil, false
+	}
+
+	return newDecimal(value, *digits), true
+}
+
+func fractionDigits(s string) int {
+	s = strings.TrimSpace(s)
+
+	if exp := strings.IndexAny(s, "eE"); exp >= 0 {
+		s = s[:exp]
+	}
+
+	dot := strings.IndexByte(s, '.')
+	if dot < 0 {
+		return 0
+	}
+
+	return len(s) - dot - 1
+}
+
+// decimal is a number with fixed amount of visible fraction digits.
+type decimal struct {
+	number.Formatter
+
+	digits []byte
+	exp    int
+	scale  int
+}
+
+func newDecimal(value float64, scale int) decimal {
+	text := strconv.FormatFloat(math.Abs(value), 'f', scale, 64)
+
+	dec := decimal{
+		Formatter: number.Decimal(value, number.Scale(scale)),
+		digits:    make([]byte, 0, len(text)),
+		exp:       len(text),
+		scale:     scale,
+	}
+
+	for idx := 0; idx < len(text); idx++ {
+		if text[idx] == '.' {
+			dec.exp = idx
+
+			continue
+		}
+
+		dec.digits = append(dec.digits, text[idx]-'0')
+	}
+
+	return dec
+}
+
+// Digits hides number.Formatter.Digits, which drops trailing zeros, so plural
+// form is selected by PluralForm instead.
+func (d decimal) Digits() {}
+
+func (d decimal) PluralForm(tag language.Tag, _ int) (plural.Form, int) {
+	n := 0
+	for idx := 0; idx < d.exp && idx < len(d.digits); idx++ {
+		n = (n*10 + int(d.digits[idx])) % 10000000
+	}
+
+	return plural.Cardinal.MatchDigits(tag, d.digits, d.exp, d.scale), n
+}
@@ -0,0 +1,92 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestDecimalPlurals(t *testing.T) {
+	t.Parallel()
+
+	builder, err := InitBuilder(context.Background(), fstest.MapFS{
+		"locales/ru/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "apples", "plural": {"one": "%v яблоко", "few": "%v яблока", "many": "%v яблок", "other": "%v яблока (дробное)"}},
+  {"key": "weight", "digits": 1, "plural": {"one": "%v килограмм", "few": "%v килограмма", "many": "%v килограммов", "other": "%v килограмма (дробное)"}}
+]`)},
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "apples", "plural": {"one": "%v apple", "other": "%v apples"}}
+]`)},
+	})
+	require.NoError(t, err)
+
+	sprintf := func(lang language.Tag, key string, args ...interface{}) string {
+		return message.NewPrinter(lang, message.Catalog(builder)).Sprintf(key, PrepareArgs(builder, lang, key, args)...)
+	}
+
+	t.Run("integers", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "1 яблоко", sprintf(language.Russian, "apples", 1))
+		assert.Equal(t, "2 яблока", sprintf(language.Russian, "apples", 2))
+		assert.Equal(t, "5 яблок", sprintf(language.Russian, "apples", 5))
+		assert.Equal(t, "1 apple", sprintf(language.English, "apples", 1))
+	})
+
+	t.Run("string decimals", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "1,5 яблока (дробное)", sprintf(language.Russian, "apples", "1.5"))
+		assert.Equal(t, "2,0 яблока (дробное)", sprintf(language.Russian, "apples", "2.0"))
+		assert.Equal(t, "5 яблок", sprintf(language.Russian, "apples", "5"))
+		assert.Equal(t, "1.0 apples", sprintf(language.English, "apples", "1.0"))
+		assert.Equal(t, "1 apple", sprintf(language.English, "apples", "1"))
+	})
+
+	t.Run("float decimals", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "5 яблок", sprintf(language.Russian, "apples", 5.0))
+		assert.Equal(t, "1 яблоко", sprintf(language.Russian, "apples", 1.0))
+		assert.Equal(t, "1,5 яблока (дробное)", sprintf(language.Russian, "apples", 1.5))
+		assert.Equal(t, "2,25 яблока (дробное)", sprintf(language.Russian, "apples", float32(2.25)))
+		assert.Equal(t, "1 apple", sprintf(language.English, "apples", 1.0))
+	})
+
+	t.Run("fixed digits", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "1,0 килограмма (дробное)", sprintf(language.Russian, "weight", 1))
+		assert.Equal(t, "1,5 килограмма (дробное)", sprintf(language.Russian, "weight", 1.5))
+		assert.Equal(t, "2,3 килограмма (дробное)", sprintf(language.Russian, "weight", "2.26"))
+	})
+}
+
+func TestDecimalPluralsPerLanguage(t *testing.T) {
+	t.Parallel()
+
+	builder, err := InitBuilder(context.Background(), fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "weight", "digits": 1, "plural": {"one": "%v kilogram", "other": "%v kilograms"}}
+]`)},
+		"locales/ru/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "weight", "digits": 2, "plural": {"one": "%v килограмм", "few": "%v килограмма", "many": "%v килограммов", "other": "%v килограмма"}}
+]`)},
+	})
+	require.NoError(t, err)
+
+	sprintf := func(lang language.Tag, key string, args ...interface{}) string {
+		return message.NewPrinter(lang, message.Catalog(builder)).Sprintf(key, PrepareArgs(builder, lang, key, args)...)
+	}
+
+	assert.Equal(t, "1.5 kilograms", sprintf(language.English, "weight", 1.5))
+	assert.Equal(t, "1,50 килограмма", sprintf(language.Russian, "weight", 1.5))
+	assert.Equal(t, "1,50 килограмма", sprintf(language.MustParse("ru-KZ"), "weight", 1.5))
+}
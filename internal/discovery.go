@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// WithDiscovery enables fetching the list of remote languages from the index path relative to the base URL.
+// The index must respond with a JSON array of language tags.
+func (e *ExternalLoader) WithDiscovery(indexPath string) *ExternalLoader {
+	e.discovery = true
+	e.indexPath = indexPath
+
+	return e
+}
+
+func (e *ExternalLoader) languages(ctx context.Context, known []language.Tag) ([]language.Tag, error) {
+	if only, ok := languagesFrom(ctx); ok {
+		return only, nil
+	}
+
+	if !e.discovery {
+		return known, nil
+	}
+
+	discovered, err := e.discover(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "discover languages")
+	}
+
+	return mergeLanguages(known, discovered), nil
+}
+
+// discoverLanguages returns languages of the index, languages of the cache if the remote is unavailable and
+// the cache policy allows it. Nil is returned without discovery.
+func (e *ExternalLoader) discoverLanguages(ctx context.Context) ([]language.Tag, error) {
+	if !e.discovery {
+		return nil, nil
+	}
+
+	languages, err := e.discover(ctx)
+	if err != nil && e.cache != nil && e.cache.Policy == CacheUse && remoteUnavailable(err) {
+		return e.cachedLanguages()
+	}
+
+	return languages, err
+}
+
+func (e *ExternalLoader) discover(ctx context.Context) ([]language.Tag, error) {
+	indexURL, err := url.JoinPath(e.baseURL, e.indexPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "join url path")
+	}
+
+	var languages []language.Tag
+
+	err = e.withRetry(ctx, func(ctx context.Context) error {
+		response, err := e.fetch(ctx, indexURL, false, nil)
+		if err != nil {
+			return err
+		}
+
+		if e.verifier != nil {
+			if err := e.verifier.verifyIndex(ctx, e, indexURL, response.body); err != nil {
+				return errors.WithMessage(err, "verify languages index")
+			}
+		}
+
+		var names []string
+		if err := json.Unmarshal(response.body, &names); err != nil {
+			return errors.Wrap(err, "decode languages index")
+		}
+
+		languages = make([]language.Tag, 0, len(names))
+
+		for _, name := range names {
+			lang, err := language.Parse(name)
+			if err != nil {
+				return errors.Wrapf(err, "parse language %s", name)
+			}
+
+			languages = append(languages, lang)
+		}
+
+		return nil
+	})
+
+	return languages, err
+}
+
+func mergeLanguages(known, discovered []language.Tag) []language.Tag {
+	merged := make([]language.Tag, 0, len(known)+len(discovered))
+	seen := make(map[language.Tag]struct{}, len(known)+len(discovered))
+
+	for _, languages := range [][]language.Tag{known, discovered} {
+		for _, lang := range languages {
+			if _, ok := seen[lang]; ok {
+				continue
+			}
+
+			seen[lang] = struct{}{}
+			merged = append(merged, lang)
+		}
+	}
+
+	return merged
+}
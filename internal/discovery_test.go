@@ -0,0 +1,53 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestExternalLoader_Discovery(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`["en", "de"]`))
+		case "/en":
+			_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Published"}]`))
+		case "/de":
+			_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Veröffentlicht"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Published"}]`))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []language.Tag{language.English}, builder.Languages())
+
+	t.Run("disabled", func(t *testing.T) {
+		require.NoError(t, NewExternalLoader(srv.URL, nil).Load(context.Background(), builder))
+		assert.ElementsMatch(t, []language.Tag{language.English}, builder.Languages())
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		require.NoError(t, NewExternalLoader(srv.URL, nil).WithDiscovery("/").Load(context.Background(), builder))
+		assert.ElementsMatch(t, []language.Tag{language.English, language.German}, builder.Languages())
+
+		assert.Equal(t, "Veröffentlicht", message.NewPrinter(language.German, message.Catalog(builder)).Sprintf("Published"))
+	})
+
+	t.Run("bad index", func(t *testing.T) {
+		require.Error(t, NewExternalLoader(srv.URL, nil).WithDiscovery("/en").Load(context.Background(), builder))
+	})
+}
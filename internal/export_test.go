@@ -0,0 +1,4 @@
+package internal
+
+// UnregisterBundle exposes unregisterBundle to tests of the package.
+var UnregisterBundle = unregisterBundle
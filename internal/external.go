@@ -1,10 +1,12 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,6 +16,8 @@ import (
 
 var ErrInvalidResponseCode = errors.New("invalid response status code")
 
+const defaultRequestTimeout = 10 * time.Second
+
 func NewExternalLoader(baseURL string, header http.Header) *ExternalLoader {
 	client := http.Client{
 		Transport: &http.Transport{
@@ -37,57 +41,475 @@ func NewExternalLoader(baseURL string, header http.Header) *ExternalLoader {
 }
 
 func NewExternalLoaderWithClient(baseURL string, header http.Header, client *http.Client) *ExternalLoader {
-	return &ExternalLoader{baseURL: baseURL, client: client, header: header}
+	return &ExternalLoader{
+		timeout:      defaultRequestTimeout,
+		maxBodySize:  defaultMaxBodySize,
+		contentTypes: defaultContentTypes,
+		baseURL:      baseURL,
+		client:       client,
+		header:       header,
+		validators:   map[language.Tag]validator{},
+	}
+}
+
+// loadRun is the state of a single Load call.
+type loadRun struct {
+	builder *catalog.Builder
+	check   bundleCheck
+}
+
+// validator keeps cache validators of the last applied response for a language.
+type validator struct {
+	builder      *catalog.Builder
+	etag         string
+	lastModified string
 }
 
 type ExternalLoader struct {
 	baseURL string
 	header  http.Header
 	client  *http.Client
+
+	retry       RetryPolicy
+	timeout     time.Duration
+	parallelism int
+	discovery   bool
+	indexPath   string
+	cache       *CacheOptions
+	tolerant    bool
+	decorators  []RequestDecorator
+	verifier    bundleVerifier
+	validation  *ValidationPolicy
+
+	maxBodySize  int64
+	contentTypes []string
+
+	applyMu sync.Mutex
+
+	mu         sync.Mutex
+	validators map[language.Tag]validator
+	stats      []LanguageStat
 }
 
-func (e *ExternalLoader) Load(builder *catalog.Builder) error {
-	languages := builder.Languages()
+// LanguageStat describes the last load of a single language.
+type LanguageStat struct {
+	Language language.Tag
+	Duration time.Duration
+	Attempts int
+	Changed  bool
+	Err      error
 
-	for _, lang := range languages {
-		if err := e.load(lang, builder); err != nil {
-			return errors.WithMessagef(err, "load translation for %s", lang.String())
+	// Rejected are messages of the applied bundle rejected by the validation policy.
+	Rejected []RejectedMessage
+
+	// RemoteErr is the remote failure which was tolerated by the cache policy.
+	RemoteErr error
+	FromCache bool
+	CachedAt  time.Time
+	CacheErr  error
+}
+
+// WithContinueOnError makes loader continue past failing languages, failures are returned as LoadErrors.
+func (e *ExternalLoader) WithContinueOnError(enabled bool) *ExternalLoader {
+	e.tolerant = enabled
+
+	return e
+}
+
+// WithParallelism sets how many languages are fetched concurrently.
+func (e *ExternalLoader) WithParallelism(parallelism int) *ExternalLoader {
+	e.parallelism = parallelism
+
+	return e
+}
+
+// LastStats returns per-language information about the last load.
+func (e *ExternalLoader) LastStats() []LanguageStat {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats := make([]LanguageStat, len(e.stats))
+	copy(stats, e.stats)
+
+	return stats
+}
+
+// WithTimeout sets timeout of a single request attempt, zero disables it.
+func (e *ExternalLoader) WithTimeout(timeout time.Duration) *ExternalLoader {
+	e.timeout = timeout
+
+	return e
+}
+
+// WithRetryPolicy sets the policy of retrying failed requests. Must be called before loader is used.
+func (e *ExternalLoader) WithRetryPolicy(policy RetryPolicy) *ExternalLoader {
+	e.retry = policy
+
+	return e
+}
+
+func (e *ExternalLoader) Load(ctx context.Context, builder *catalog.Builder) error {
+	_, err := e.LoadChanged(ctx, builder)
+
+	return err
+}
+
+// LoadChanged loads translations and reports whether any language was actually changed.
+func (e *ExternalLoader) LoadChanged(ctx context.Context, builder *catalog.Builder) (bool, error) {
+	languages, err := e.languages(ctx, Languages(builder))
+	if err != nil {
+		return e.unreachable(ctx, builder, err)
+	}
+
+	run := &loadRun{builder: builder}
+
+	if e.verifier != nil {
+		if run.check, err = e.verifier.begin(ctx, e); err != nil {
+			return e.unreachable(ctx, builder, errors.WithMessage(err, "prepare bundle verification"))
+		}
+	}
+
+	return e.finish(e.loadLanguages(ctx, languages, run))
+}
+
+// finish saves stats of the load and reports its result.
+func (e *ExternalLoader) finish(stats []LanguageStat, firstErr *LanguageStat) (bool, error) {
+	e.mu.Lock()
+	e.stats = stats
+	e.mu.Unlock()
+
+	var changed bool
+
+	for _, stat := range stats {
+		changed = changed || stat.Changed
+	}
+
+	if !e.tolerant && firstErr != nil {
+		return changed, errors.WithMessagef(firstErr.Err, "load translation for %s", firstErr.Language.String())
+	}
+
+	var errs LoadErrors
+
+	for _, stat := range stats {
+		source, _ := e.languageURL(stat.Language)
+
+		if stat.Err != nil {
+			errs.Errors = append(errs.Errors, &LoadError{Language: stat.Language, Source: source, Err: stat.Err})
+		}
+
+		if len(stat.Rejected) > 0 {
+			errs.Errors = append(errs.Errors, &LoadError{
+				Language: stat.Language,
+				Source:   source,
+				Err:      &ValidationError{Language: stat.Language, Rejected: stat.Rejected},
+			})
 		}
 	}
 
-	return nil
+	return changed, errs.errOrNil()
 }
 
-func (e *ExternalLoader) load(lang language.Tag, builder *catalog.Builder) error {
+func (e *ExternalLoader) languageURL(lang language.Tag) (string, error) {
 	langURL, err := url.JoinPath(e.baseURL, lang.String())
 	if err != nil {
-		return errors.Wrap(err, "join url path")
+		return "", errors.Wrap(err, "join url path")
+	}
+
+	return langURL, nil
+}
+
+// loadLanguages loads the reference language of the validation policy first, so other languages are validated
+// against its new messages, and then the rest of languages.
+func (e *ExternalLoader) loadLanguages(ctx context.Context, languages []language.Tag, run *loadRun) ([]LanguageStat, *LanguageStat) {
+	var reference, others []language.Tag
+
+	for _, lang := range languages {
+		if e.validation.isReference(lang) {
+			reference = append(reference, lang)
+		} else {
+			others = append(others, lang)
+		}
+	}
+
+	stats := make([]LanguageStat, len(languages))
+
+	if len(reference) == 0 || len(others) == 0 {
+		return stats, e.loadConcurrently(ctx, languages, stats, run)
+	}
+
+	firstErr := e.loadConcurrently(ctx, reference, stats[:len(reference)], run)
+	if firstErr != nil && !e.tolerant {
+		return stats[:len(reference)], firstErr
+	}
+
+	if restErr := e.loadConcurrently(ctx, others, stats[len(reference):], run); firstErr == nil {
+		firstErr = restErr
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	return stats, firstErr
+}
+
+// loadConcurrently fetches languages concurrently into stats, stopping at the first failure unless loader
+// is tolerant.
+func (e *ExternalLoader) loadConcurrently(ctx context.Context, languages []language.Tag, stats []LanguageStat, run *loadRun) *LanguageStat {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, langURL, nil)
+	parallelism := e.parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var (
+		sem      = make(chan struct{}, parallelism)
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr *LanguageStat
+	)
+
+	for idx, lang := range languages {
+		stats[idx].Language = lang
+		sem <- struct{}{}
+
+		wg.Add(1)
+
+		go func(stat *LanguageStat) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := ctx.Err(); err != nil {
+				stat.Err = err
+
+				return
+			}
+
+			started := time.Now()
+			stat.Changed, stat.Attempts, stat.Err = e.load(ctx, stat, run)
+
+			if stat.Err != nil && ctx.Err() == nil {
+				e.fallback(ctx, stat.Language, run.builder, stat)
+			}
+
+			stat.Duration = time.Since(started)
+
+			if stat.Err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = stat
+				}
+				errMu.Unlock()
+
+				if !e.tolerant {
+					cancel()
+				}
+			}
+		}(&stats[idx])
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
+
+func (e *ExternalLoader) load(ctx context.Context, stat *LanguageStat, run *loadRun) (bool, int, error) {
+	var (
+		changed  bool
+		attempts int
+	)
+
+	err := e.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+
+		attempts++
+		changed, err = e.attempt(ctx, stat, run)
+
+		return err
+	})
+
+	return changed, attempts, err
+}
+
+func (e *ExternalLoader) attempt(ctx context.Context, stat *LanguageStat, run *loadRun) (bool, error) {
+	lang, builder := stat.Language, run.builder
+
+	langURL, err := e.languageURL(lang)
+	if err != nil {
+		return false, err
+	}
+
+	response, err := e.fetch(ctx, langURL, false, func(req *http.Request) {
+		if valid, ok := e.validator(lang, builder); ok {
+			if valid.etag != "" {
+				req.Header.Set("If-None-Match", valid.etag)
+			}
+
+			if valid.lastModified != "" {
+				req.Header.Set("If-Modified-Since", valid.lastModified)
+			}
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if response.status == http.StatusNotModified {
+		stat.CacheErr = e.touchCache(lang)
+
+		return false, nil
+	}
+
+	var proof *bundleProof
+
+	if run.check != nil {
+		if proof, err = run.check(ctx, lang, langURL, response.body); err != nil {
+			return false, errors.WithMessage(err, "verify bundle")
+		}
+	}
+
+	if stat.Rejected, err = e.apply(ctx, response.body, lang, builder); err != nil {
+		return false, errors.WithMessage(err, "load translation")
+	}
+
+	stat.CacheErr = e.storeCache(lang, langURL, response, proof)
+
+	e.setValidator(lang, validator{
+		builder:      builder,
+		etag:         response.header.Get("ETag"),
+		lastModified: response.header.Get("Last-Modified"),
+	})
+
+	return true, nil
+}
+
+// do sends a decorated GET request.
+func (e *ExternalLoader) do(ctx context.Context, target string, prepare func(req *http.Request)) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 	if err != nil {
-		return errors.Wrap(err, "new request")
+		return nil, errors.Wrap(err, "new request")
 	}
 
 	if e.header != nil {
-		req.Header = e.header
+		req.Header = e.header.Clone()
+	}
+
+	if prepare != nil {
+		prepare(req)
+	}
+
+	if err := e.decorate(req); err != nil {
+		return nil, err
+	}
+
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
 	}
 
 	response, err := e.client.Do(req)
 	if err != nil {
-		return errors.Wrap(err, "do request")
+		return nil, &retryableError{err: &transportError{err: errors.Wrap(err, "do request")}}
 	}
 
-	if response.StatusCode != http.StatusOK {
-		return errors.Wrapf(ErrInvalidResponseCode, "got status %d", response.StatusCode)
+	return response, nil
+}
+
+type fetched struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// fetch does a GET request, it is repeated once if credentials were rejected and invalidated. Response body
+// is read only for 200 OK. Content type is not checked for raw.
+func (e *ExternalLoader) fetch(ctx context.Context, target string, raw bool, prepare func(req *http.Request)) (*fetched, error) {
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	response, err := e.do(ctx, target, prepare)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized && e.invalidate(response.Request) {
+		closeBody(response.Body)
+
+		if response, err = e.do(ctx, target, prepare); err != nil {
+			return nil, err
+		}
+	}
+
+	defer closeBody(response.Body)
+
+	result := fetched{status: response.StatusCode, header: response.Header}
+
+	switch {
+	case response.StatusCode == http.StatusNotModified:
+		return &result, nil
+
+	case response.StatusCode != http.StatusOK:
+		err := responseError(target, response)
+
+		if e.retry.retryableStatus(response.StatusCode) {
+			return nil, &retryableError{err: err, after: retryAfter(response.Header.Get("Retry-After"), time.Now())}
+		}
+
+		return nil, err
 	}
 
-	if err := load(response.Body, lang, builder); err != nil {
-		return errors.WithMessage(err, "load translation")
+	if !raw {
+		if err := e.checkContentType(response.Header); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	if result.body, err = e.readBody(response); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// WithValidation makes loader apply only messages accepted by the policy. Rejected messages do not stop the
+// load, they are reported as LoadErrors with ValidationError, for which IsPartial is true.
+func (e *ExternalLoader) WithValidation(policy ValidationPolicy) *ExternalLoader {
+	e.validation = &policy
+
+	return e
+}
+
+// apply loads the bundle, messages rejected by the validation policy are returned.
+func (e *ExternalLoader) apply(ctx context.Context, data []byte, lang language.Tag, builder *catalog.Builder) ([]RejectedMessage, error) {
+	e.applyMu.Lock()
+	defer e.applyMu.Unlock()
+
+	err := load(ctx, bytes.NewReader(data), lang, builder, e.validation)
+
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		return invalid.Rejected, nil
+	}
+
+	return nil, err
+}
+
+func (e *ExternalLoader) validator(lang language.Tag, builder *catalog.Builder) (validator, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	valid, ok := e.validators[lang]
+	if !ok || valid.builder != builder {
+		return validator{}, false
+	}
+
+	return valid, true
+}
+
+func (e *ExternalLoader) setValidator(lang language.Tag, valid validator) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.validators[lang] = valid
 }
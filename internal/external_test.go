@@ -0,0 +1,125 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestExternalLoader_ConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	var (
+		version  atomic.Value
+		requests int32
+		notMod   int32
+	)
+
+	version.Store("v1")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		etag := `"` + version.Load().(string) + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notMod, 1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Published ` + version.Load().(string) + `"}]`))
+	}))
+	defer srv.Close()
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Published"}]`))
+	require.NoError(t, err)
+
+	loader := NewExternalLoader(srv.URL, http.Header{"X-Test": []string{"1"}})
+	printer := message.NewPrinter(language.English, message.Catalog(builder))
+
+	changed, err := loader.LoadChanged(context.Background(), builder)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Published v1", printer.Sprintf("Published"))
+
+	changed, err = loader.LoadChanged(context.Background(), builder)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notMod))
+	assert.Equal(t, "Published v1", printer.Sprintf("Published"))
+
+	version.Store("v2")
+
+	changed, err = loader.LoadChanged(context.Background(), builder)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Published v2", printer.Sprintf("Published"))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	t.Run("other builder", func(t *testing.T) {
+		other, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Published"}]`))
+		require.NoError(t, err)
+
+		changed, err := loader.LoadChanged(context.Background(), other)
+		require.NoError(t, err)
+		assert.True(t, changed)
+	})
+}
+
+func TestExternalLoader_Context(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Published"}]`))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+
+	err = NewExternalLoader(srv.URL, nil).WithRetryPolicy(DefaultRetryPolicy()).Load(ctx, builder)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+type legacyLoader struct{}
+
+func (legacyLoader) Load(cat *catalog.Builder) error {
+	return cat.SetString(language.English, "Published", "Legacy")
+}
+
+func TestLoaderWithoutContext(t *testing.T) {
+	t.Parallel()
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Published"}]`))
+	require.NoError(t, err)
+
+	require.NoError(t, LoaderWithoutContext(legacyLoader{}).Load(context.Background(), builder))
+	assert.Equal(t, "Legacy", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+}
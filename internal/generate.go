@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"bytes"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GenerateGo writes Go source declaring the locales as a variable of the package, to be passed to InitLocales.
+// Helpers of the source are named after the variable, so several generated files may share a package.
+func GenerateGo(w io.Writer, pkg, variable string, locales []Locale) error {
+	var (
+		src          strings.Builder
+		useDigits    bool
+		digitsHelper = "goi18n" + variable + "Digits"
+	)
+
+	digits := func(val *int) string {
+		useDigits = true
+
+		return digitsHelper + "(" + strconv.Itoa(*val) + ")"
+	}
+
+	src.WriteString("// Code generated by goi18n-gen. DO NOT EDIT.\n\n")
+	src.WriteString("package " + pkg + "\n\n")
+	src.WriteString("import i18n \"github.com/derfenix/goi18n\"\n\n")
+	src.WriteString("var " + variable + " = []i18n.Locale{\n")
+
+	for _, locale := range locales {
+		src.WriteString("{\nLanguage: " + strconv.Quote(locale.Language) + ",\nMessages: []i18n.Message{\n")
+
+		for _, msg := range locale.Messages {
+			src.WriteString("{\n")
+			writeField(&src, "Key", strconv.Quote(msg.Key))
+
+			if msg.Description != "" {
+				writeField(&src, "Description", strconv.Quote(msg.Description))
+			}
+
+			if msg.Translation != "" {
+				writeField(&src, "Translation", strconv.Quote(msg.Translation))
+			}
+
+			if msg.Plural != nil {
+				writeField(&src, "Plural", cases(msg.Plural))
+			}
+
+			if len(msg.Placeholders) > 0 {
+				quoted := make([]string, 0, len(msg.Placeholders))
+				for _, name := range msg.Placeholders {
+					quoted = append(quoted, strconv.Quote(name))
+				}
+
+				writeField(&src, "Placeholders", "[]string{"+strings.Join(quoted, ", ")+"}")
+			}
+
+			if msg.PluralArg != "" {
+				writeField(&src, "PluralArg", strconv.Quote(msg.PluralArg))
+			}
+
+			if msg.Digits != nil {
+				writeField(&src, "Digits", digits(msg.Digits))
+			}
+
+			if len(msg.Plurals) > 0 {
+				src.WriteString("Plurals: map[string]i18n.NestedPlural{\n")
+
+				for _, name := range sortedKeys(msg.Plurals) {
+					nested := msg.Plurals[name]
+
+					src.WriteString(strconv.Quote(name) + ": {\n")
+
+					if nested.Arg != 0 {
+						writeField(&src, "Arg", strconv.Itoa(nested.Arg))
+					}
+
+					if nested.Digits != nil {
+						writeField(&src, "Digits", digits(nested.Digits))
+					}
+
+					writeField(&src, "Cases", cases(nested.Cases))
+					src.WriteString("},\n")
+				}
+
+				src.WriteString("},\n")
+			}
+
+			src.WriteString("},\n")
+		}
+
+		src.WriteString("},\n},\n")
+	}
+
+	src.WriteString("}\n")
+
+	if useDigits {
+		src.WriteString("\nfunc " + digitsHelper + "(n int) *int { return &n }\n")
+	}
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return errors.Wrap(err, "format source")
+	}
+
+	if _, err := io.Copy(w, bytes.NewReader(formatted)); err != nil {
+		return errors.Wrap(err, "write source")
+	}
+
+	return nil
+}
+
+func writeField(src *strings.Builder, name, value string) {
+	src.WriteString(name + ": " + value + ",\n")
+}
+
+func cases(values map[string]string) string {
+	items := make([]string, 0, len(values))
+	for _, cond := range sortedKeys(values) {
+		items = append(items, strconv.Quote(cond)+": "+strconv.Quote(values[cond]))
+	}
+
+	return "map[string]string{" + strings.Join(items, ", ") + "}"
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
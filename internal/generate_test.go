@@ -0,0 +1,82 @@
+package internal_test
+
+import (
+	"bytes"
+	"context"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+const generateLocale = `[
+  {"key": "$unit", "translation": "item"},
+  {"key": "Greeting", "placeholders": ["name"], "translation": "Hello, \"{name}\""},
+  {"key": "Items", "plural": {"=0": "no ${unit}s", "one": "%d ${unit}", "other": "%d ${unit}s"}},
+  {"key": "Weight", "digits": 1, "plural": {"one": "%.1f kilogram", "other": "%.1f kilograms"}},
+  {
+    "key": "files in folders",
+    "translation": "${files} in ${folders}",
+    "plurals": {
+      "files": {"arg": 1, "one": "%[1]d file", "other": "%[1]d files"},
+      "folders": {"arg": 2, "one": "%[2]d folder", "other": "%[2]d folders"}
+    }
+  }
+]`
+
+func TestInitBuilderLocales(t *testing.T) {
+	t.Parallel()
+
+	files := localeFS(generateLocale)
+
+	want, err := InitBuilder(context.Background(), files)
+	require.NoError(t, err)
+
+	locales, err := ReadLocales(files)
+	require.NoError(t, err)
+
+	got, err := InitBuilderLocales(context.Background(), locales)
+	require.NoError(t, err)
+
+	assert.Equal(t, want.Languages(), got.Languages())
+
+	wantPrinter := message.NewPrinter(language.English, message.Catalog(want))
+	gotPrinter := message.NewPrinter(language.English, message.Catalog(got))
+
+	assert.Equal(t, wantPrinter.Sprintf("Greeting", NamedArgs(want, "Greeting", map[string]string{"name": "John"})...),
+		gotPrinter.Sprintf("Greeting", NamedArgs(got, "Greeting", map[string]string{"name": "John"})...))
+
+	for _, count := range []int{0, 1, 5} {
+		assert.Equal(t, wantPrinter.Sprintf("Items", count), gotPrinter.Sprintf("Items", count))
+	}
+
+	assert.Equal(t, wantPrinter.Sprintf("Weight", PrepareArgs(want, language.English, "Weight", []interface{}{"1.0"})...),
+		gotPrinter.Sprintf("Weight", PrepareArgs(got, language.English, "Weight", []interface{}{"1.0"})...))
+	assert.Equal(t, wantPrinter.Sprintf("files in folders", 1, 2), gotPrinter.Sprintf("files in folders", 1, 2))
+}
+
+func TestGenerateGo(t *testing.T) {
+	t.Parallel()
+
+	locales, err := ReadLocales(localeFS(generateLocale))
+	require.NoError(t, err)
+
+	var src bytes.Buffer
+
+	require.NoError(t, GenerateGo(&src, "locales", "Locales", locales))
+
+	file, err := parser.ParseFile(token.NewFileSet(), "locales_gen.go", src.Bytes(), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "locales", file.Name.Name)
+	assert.Contains(t, src.String(), `"Hello, \"{name}\""`)
+	assert.Contains(t, src.String(), `map[string]string{"=0": "no ${unit}s", "one": "%d ${unit}", "other": "%d ${unit}s"}`)
+	assert.Contains(t, src.String(), "goi18nLocalesDigits(1)")
+	assert.Contains(t, src.String(), "func goi18nLocalesDigits(n int) *int")
+}
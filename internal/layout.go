@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	langPlaceholder = "{lang}"
+	globMeta        = `*?[\`
+)
+
+var ErrInvalidLayout = errors.New("invalid layout")
+
+var (
+	defaultLayout = []string{"locales/{lang}/active.json"}
+	layout        = defaultLayout
+)
+
+// SetLayout sets patterns of locale file paths, e.g. "locales/{lang}.json" or "i18n/*/active.{lang}.json".
+// A pattern has a single {lang} placeholder and may use path.Match syntax elsewhere. No patterns restore
+// the default locales/{lang}/active.json layout. If a pattern has no wildcards and {lang} is a whole dir, as
+// the default one, a language dir without the file is an error. A missing root dir of a pattern is an error
+// only if no other pattern matched files.
+func SetLayout(patterns ...string) error {
+	if len(patterns) == 0 {
+		layout = defaultLayout
+
+		return nil
+	}
+
+	if err := validateLayout(patterns); err != nil {
+		return err
+	}
+
+	layout = patterns
+
+	return nil
+}
+
+func validateLayout(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.Count(pattern, langPlaceholder) != 1 {
+			return errors.Wrapf(ErrInvalidLayout, "pattern %q must have exactly one %s", pattern, langPlaceholder)
+		}
+
+		if _, err := path.Match(strings.Replace(pattern, langPlaceholder, "*", 1), ""); err != nil {
+			return errors.Wrapf(ErrInvalidLayout, "pattern %q: %s", pattern, err.Error())
+		}
+
+		segment := pattern[strings.LastIndex(pattern[:strings.Index(pattern, langPlaceholder)], "/")+1:]
+		if end := strings.IndexByte(segment, '/'); end >= 0 {
+			segment = segment[:end]
+		}
+
+		if strings.ContainsAny(segment, globMeta) {
+			return errors.Wrapf(ErrInvalidLayout, "pattern %q: %s segment can not have wildcards", pattern, langPlaceholder)
+		}
+	}
+
+	return nil
+}
+
+// localePath is a file matched by the layout.
+type localePath struct {
+	name string
+	path string
+}
+
+// findLayout returns files matching the patterns. A pattern whose root dir does not exist matches nothing, so
+// layers may have only some of the roots, the error is returned only if no pattern matched anything.
+func findLayout(files fs.FS, patterns []string) ([]localePath, error) {
+	var (
+		found   []localePath
+		missing error
+	)
+
+	for _, pattern := range patterns {
+		matched, err := findLocales(files, pattern)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+
+			if missing == nil {
+				missing = err
+			}
+
+			continue
+		}
+
+		found = append(found, matched...)
+	}
+
+	if len(found) == 0 && missing != nil {
+		return nil, missing
+	}
+
+	return found, nil
+}
+
+// findLocales returns files matching the pattern. Hidden entries are skipped unless pattern names them. If
+// the pattern has no wildcards and {lang} is a whole dir, e.g. the default layout, every language dir must
+// have the file.
+func findLocales(files fs.FS, pattern string) ([]localePath, error) {
+	segments := strings.Split(pattern, "/")
+
+	var (
+		langIdx int
+		root    []string
+	)
+
+	for idx, segment := range segments {
+		if strings.Contains(segment, langPlaceholder) {
+			langIdx = idx
+
+			break
+		}
+	}
+
+	for _, segment := range segments[:langIdx] {
+		if strings.ContainsAny(segment, globMeta) {
+			break
+		}
+
+		root = append(root, segment)
+	}
+
+	if dir := path.Join(root...); len(root) > 0 {
+		if _, err := fs.Stat(files, dir); err != nil {
+			return nil, errors.Wrapf(err, "read %s dir", dir)
+		}
+	}
+
+	if segments[langIdx] == langPlaceholder && !strings.ContainsAny(pattern, globMeta) {
+		return languageDirs(files, pattern, path.Join(root...))
+	}
+
+	matches, err := fs.Glob(files, strings.Replace(pattern, langPlaceholder, "*", 1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "match %s", pattern)
+	}
+
+	prefix, suffix, _ := strings.Cut(segments[langIdx], langPlaceholder)
+
+	var found []localePath
+
+	for _, match := range matches {
+		parts := strings.Split(match, "/")
+		if hidden(parts, segments) {
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(parts[langIdx], prefix), suffix)
+		if name == "" {
+			continue
+		}
+
+		if info, err := fs.Stat(files, match); err == nil && info.IsDir() {
+			continue
+		}
+
+		found = append(found, localePath{name: name, path: match})
+	}
+
+	return found, nil
+}
+
+// languageDirs returns the file of the pattern for every language dir of root, also for dirs without the file,
+// so reading it fails as it did before layouts were configurable.
+func languageDirs(files fs.FS, pattern, root string) ([]localePath, error) {
+	if root == "" {
+		root = "."
+	}
+
+	entries, err := fs.ReadDir(files, root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read %s dir", root)
+	}
+
+	var found []localePath
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if info, err := fs.Stat(files, path.Join(root, name)); err != nil || !info.IsDir() {
+			continue
+		}
+
+		found = append(found, localePath{name: name, path: strings.Replace(pattern, langPlaceholder, name, 1)})
+	}
+
+	return found, nil
+}
+
+func hidden(parts, segments []string) bool {
+	for idx := range parts {
+		if strings.HasPrefix(parts[idx], ".") && !strings.HasPrefix(segments[idx], ".") {
+			return true
+		}
+	}
+
+	return false
+}
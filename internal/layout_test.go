@@ -0,0 +1,99 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestFSLoader_Layout(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"i18n/en.json":                   &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Flat"}]`)},
+		"i18n/.backup/fr.json":           &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Hidden"}]`)},
+		"active.ru.json":                 &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Опубликовано"}]`)},
+		"modules/blog/locale.de.json":    &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Veröffentlicht"}]`)},
+		"modules/shop/locale.de.json":    &fstest.MapFile{Data: []byte(`[{"key": "Cart", "translation": "Warenkorb"}]`)},
+		"modules/shop/locale.de.json.gz": &fstest.MapFile{Data: []byte(`garbage`)},
+	}
+
+	builder := catalog.NewBuilder()
+
+	err := NewFSLoader(files).
+		WithLayout("i18n/{lang}.json", "active.{lang}.json", "modules/*/locale.{lang}.json").
+		Load(context.Background(), builder)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []language.Tag{language.English, language.Russian, language.German}, builder.Languages())
+
+	de := message.NewPrinter(language.German, message.Catalog(builder))
+	assert.Equal(t, "Veröffentlicht", de.Sprintf("Published"))
+	assert.Equal(t, "Warenkorb", de.Sprintf("Cart"))
+	assert.Equal(t, "Flat", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+	assert.Equal(t, "Опубликовано", message.NewPrinter(language.Russian, message.Catalog(builder)).Sprintf("Published"))
+}
+
+func TestFSLoader_LayoutErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, pattern := range map[string]string{
+		"no placeholder":      "locales/active.json",
+		"two placeholders":    "{lang}/{lang}.json",
+		"wildcard in segment": "locales/*{lang}.json",
+		"malformed glob":      "locales/[/{lang}.json",
+	} {
+		pattern := pattern
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := NewFSLoader(localeFS(`[]`)).WithLayout(pattern).Load(context.Background(), catalog.NewBuilder())
+			assert.True(t, errors.Is(err, ErrInvalidLayout), err)
+		})
+	}
+
+	t.Run("missing root", func(t *testing.T) {
+		t.Parallel()
+
+		err := NewFSLoader(localeFS(`[]`)).WithLayout("i18n/{lang}.json").Load(context.Background(), catalog.NewBuilder())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "read i18n dir")
+	})
+
+	t.Run("missing one of roots", func(t *testing.T) {
+		t.Parallel()
+
+		builder := catalog.NewBuilder()
+
+		err := NewFSLoader(localeFS(`[{"key": "Published", "translation": "Published"}]`)).
+			WithLayout("locales/{lang}/active.json", "i18n/{lang}.json").
+			Load(context.Background(), builder)
+		require.NoError(t, err)
+		assert.Equal(t, "Published", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		files := fstest.MapFS{
+			"locales/en/active.json": &fstest.MapFile{Data: []byte(`[]`)},
+			"locales/de/other.json":  &fstest.MapFile{Data: []byte(`[]`)},
+		}
+
+		err := NewFSLoader(files).Load(context.Background(), catalog.NewBuilder())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "read file locales/de/active.json")
+
+		require.NoError(t, NewFSLoader(files).WithLayout("locales/*/active.{lang}.json", "locales/{lang}/*.json").Load(context.Background(), catalog.NewBuilder()))
+	})
+}
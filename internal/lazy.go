@@ -0,0 +1,388 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+type languagesKey struct{}
+
+// withLanguages restricts loaders to the languages.
+func withLanguages(ctx context.Context, languages ...language.Tag) context.Context {
+	return context.WithValue(ctx, languagesKey{}, languages)
+}
+
+func languagesFrom(ctx context.Context) ([]language.Tag, bool) {
+	languages, ok := ctx.Value(languagesKey{}).([]language.Tag)
+
+	return languages, ok
+}
+
+// selectedLanguage reports whether loaders should apply the language, all are applied unless restricted.
+func selectedLanguage(ctx context.Context, lang language.Tag) bool {
+	languages, ok := languagesFrom(ctx)
+
+	return !ok || indexOfTag(languages, lang) >= 0
+}
+
+// LazyOptions enables loading of languages on first use.
+type LazyOptions struct {
+	// EvictAfter drops languages unused for the period, zero keeps them forever.
+	EvictAfter time.Duration
+	// OnError is called when a language failed to load, partially loaded translations are used anyway.
+	OnError func(lang language.Tag, err error)
+}
+
+// indexedFile is a locale file found by Init in lazy mode.
+type indexedFile struct {
+	layer int
+	localePath
+}
+
+type lazyEntry struct {
+	ready    chan struct{}
+	builder  *catalog.Builder
+	lastUsed time.Time
+	bundled  bool
+
+	loadMu      sync.Mutex
+	fingerprint [sha256.Size]byte
+	// evicted is set under loadMu, loads of the entry started before the eviction bail out.
+	evicted bool
+}
+
+// LazyCatalog indexes locale files and loads every language into its own builder on first use. Builders of
+// all languages share metadata of the root builder.
+type LazyCatalog struct {
+	root      *catalog.Builder
+	layers    []fs.ReadDirFS
+	options   LazyOptions
+	index     map[language.Tag][]indexedFile
+	languages []language.Tag
+	matcher   language.Matcher
+
+	mu        sync.Mutex
+	entries   map[language.Tag]*lazyEntry
+	lastSweep time.Time
+}
+
+// languageDiscoverer is a loader able to list its languages without loading them.
+type languageDiscoverer interface {
+	discoverLanguages(ctx context.Context) ([]language.Tag, error)
+}
+
+// InitLazy indexes locale files of the layers and languages discovered by the external loader without
+// loading them.
+func InitLazy(ctx context.Context, options LazyOptions, layers ...fs.ReadDirFS) (*LazyCatalog, error) {
+	lazy := &LazyCatalog{
+		root:    catalog.NewBuilder(),
+		layers:  layers,
+		options: options,
+		index:   map[language.Tag][]indexedFile{},
+		entries: map[language.Tag]*lazyEntry{},
+	}
+
+	var errs LoadErrors
+
+	for idx, files := range layers {
+		found, err := findLayout(files, layout)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "index layer %d", idx)
+		}
+
+		for _, item := range found {
+			lang, err := language.Parse(item.name)
+			if err != nil {
+				err = &LoadError{Language: language.Und, Source: item.path, Err: errors.Wrapf(err, "parse language %s", item.name)}
+				if !continueOnError {
+					return nil, err
+				}
+
+				errs.add(err)
+
+				continue
+			}
+
+			if _, ok := lazy.index[lang]; !ok {
+				lazy.languages = append(lazy.languages, lang)
+			}
+
+			lazy.index[lang] = append(lazy.index[lang], indexedFile{layer: idx, localePath: item})
+		}
+	}
+
+	bundled, err := bundleLanguages()
+	if err != nil {
+		return nil, err
+	}
+
+	if discoverer, ok := extLoader.(languageDiscoverer); ok {
+		discovered, err := discoverer.discoverLanguages(ctx)
+		if err != nil {
+			err = &LoadError{Language: language.Und, Source: "external", Err: errors.WithMessage(err, "discover languages")}
+			if !continueOnError {
+				return nil, err
+			}
+
+			errs.add(err)
+		}
+
+		bundled = append(bundled, discovered...)
+	}
+
+	for _, lang := range bundled {
+		if _, ok := lazy.index[lang]; !ok {
+			lazy.languages = append(lazy.languages, lang)
+			lazy.index[lang] = nil
+		}
+	}
+
+	lazy.matcher = language.NewMatcher(lazy.languages)
+
+	return lazy, errs.errOrNil()
+}
+
+// Root returns the builder holding metadata of all languages, e.g. for NamedArgs and PrepareArgs.
+func (l *LazyCatalog) Root() *catalog.Builder {
+	return l.root
+}
+
+// Languages returns indexed languages, loaded or not.
+func (l *LazyCatalog) Languages() []language.Tag {
+	return l.languages
+}
+
+// Builder returns the builder of the best matching indexed language, loading it on first use. Concurrent
+// callers wait for the single load.
+func (l *LazyCatalog) Builder(ctx context.Context, tag language.Tag) *catalog.Builder {
+	if len(l.languages) == 0 {
+		return l.root
+	}
+
+	_, idx, _ := l.matcher.Match(tag)
+
+	builder, _ := l.entry(ctx, l.languages[idx])
+
+	return builder
+}
+
+// LoadAll loads every indexed language, e.g. to write all of them into a snapshot.
+func (l *LazyCatalog) LoadAll(ctx context.Context) error {
+	var errs LoadErrors
+
+	for _, lang := range l.languages {
+		if _, err := l.entry(ctx, lang); err != nil {
+			if !IsPartial(err) {
+				return errors.WithMessagef(err, "load %s", lang.String())
+			}
+
+			errs.add(err)
+		}
+	}
+
+	return errs.errOrNil()
+}
+
+// entry returns the builder of the indexed language, loading it on first use. The error is returned only to
+// the caller which loaded the language.
+func (l *LazyCatalog) entry(ctx context.Context, lang language.Tag) (*catalog.Builder, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	l.sweep(now)
+
+	entry, ok := l.entries[lang]
+	if !ok {
+		entry = &lazyEntry{ready: make(chan struct{})}
+		l.entries[lang] = entry
+	}
+
+	entry.lastUsed = now
+	l.mu.Unlock()
+
+	if ok {
+		<-entry.ready
+
+		return entry.builder, nil
+	}
+
+	entry.builder = catalog.NewBuilder()
+	shareRegistry(entry.builder, l.root)
+
+	_, err := l.load(ctx, lang, entry)
+	if err != nil && l.options.OnError != nil {
+		l.options.OnError(lang, err)
+	}
+
+	close(entry.ready)
+
+	if err != nil && !IsPartial(err) {
+		l.mu.Lock()
+		delete(l.entries, lang)
+		l.mu.Unlock()
+	}
+
+	return entry.builder, err
+}
+
+// Refresh reloads changed local files and the external loader for loaded languages.
+func (l *LazyCatalog) Refresh(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+
+	entries := make(map[language.Tag]*lazyEntry, len(l.entries))
+	for lang, entry := range l.entries {
+		entries[lang] = entry
+	}
+
+	l.mu.Unlock()
+
+	var (
+		changed bool
+		errs    LoadErrors
+	)
+
+	for lang, entry := range entries {
+		<-entry.ready
+
+		langChanged, err := l.load(ctx, lang, entry)
+		changed = changed || langChanged
+
+		if err != nil {
+			if !IsPartial(err) {
+				return changed, err
+			}
+
+			errs.add(err)
+		}
+	}
+
+	return changed, errs.errOrNil()
+}
+
+// load applies local files unless they are the same as on the previous load, then the external loader.
+func (l *LazyCatalog) load(ctx context.Context, lang language.Tag, entry *lazyEntry) (bool, error) {
+	entry.loadMu.Lock()
+	defer entry.loadMu.Unlock()
+
+	if entry.evicted {
+		return false, nil
+	}
+
+	var (
+		errs    LoadErrors
+		locales = make([]localeFile, 0, len(l.index[lang]))
+		hash    = sha256.New()
+	)
+
+	if !entry.bundled {
+		if err := applyBundles(ctx, entry.builder, lang); err != nil {
+			if !IsPartial(err) {
+				return false, err
+			}
+
+			errs.add(err)
+		}
+
+		entry.bundled = true
+	}
+
+	for _, file := range l.index[lang] {
+		locale, err := readLocale(l.layers[file.layer], file.localePath)
+		if err != nil {
+			if !continueOnError {
+				return false, err
+			}
+
+			locale.err = err
+		}
+
+		hash.Write([]byte(locale.path))
+		hash.Write([]byte{0})
+		hash.Write(locale.data)
+
+		locales = append(locales, locale)
+	}
+
+	var fingerprint [sha256.Size]byte
+
+	copy(fingerprint[:], hash.Sum(nil))
+
+	changed := entry.fingerprint != fingerprint
+
+	if changed {
+		if entry.fingerprint != ([sha256.Size]byte{}) {
+			registryOf(entry.builder).downgrade(-len(l.layers), -1, lang)
+		}
+
+		for idx, locale := range locales {
+			layerCtx := withLayer(ctx, layer{rank: l.index[lang][idx].layer - len(l.layers)})
+
+			if err := applyLocales(layerCtx, []localeFile{locale}, entry.builder, &errs); err != nil {
+				return true, err
+			}
+		}
+
+		entry.fingerprint = fingerprint
+	}
+
+	if extLoader != nil {
+		extChanged, err := loadChanged(withLanguages(ctx, lang), extLoader, entry.builder)
+		changed = changed || extChanged
+
+		if err != nil {
+			if !continueOnError || !IsPartial(err) {
+				return changed, errs.abort(errors.WithMessage(err, "load translations from external"))
+			}
+
+			errs.add(err)
+		}
+	}
+
+	if changed && extendBuilder != nil {
+		if err := extendBuilder(entry.builder); err != nil {
+			return changed, errors.Wrap(err, "extend builder")
+		}
+	}
+
+	return changed, errs.errOrNil()
+}
+
+// sweep evicts languages unused for EvictAfter, at most twice per the period. Languages being loaded are kept.
+func (l *LazyCatalog) sweep(now time.Time) {
+	if l.options.EvictAfter <= 0 || now.Sub(l.lastSweep) < l.options.EvictAfter/2 {
+		return
+	}
+
+	l.lastSweep = now
+
+	for lang, entry := range l.entries {
+		select {
+		case <-entry.ready:
+		default:
+			continue
+		}
+
+		if now.Sub(entry.lastUsed) <= l.options.EvictAfter {
+			continue
+		}
+
+		// The entry is being refreshed, it is evicted by one of the next sweeps.
+		if !entry.loadMu.TryLock() {
+			continue
+		}
+
+		entry.evicted = true
+		delete(l.entries, lang)
+		ReleaseBuilder(entry.builder)
+		registryOf(l.root).forget(lang)
+
+		entry.loadMu.Unlock()
+	}
+}
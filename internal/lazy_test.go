@@ -0,0 +1,220 @@
+package internal_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+// countingFS counts opened files.
+type countingFS struct {
+	fstest.MapFS
+	opened map[string]*int32
+}
+
+func (c countingFS) Open(name string) (fs.File, error) {
+	if counter, ok := c.opened[name]; ok {
+		atomic.AddInt32(counter, 1)
+	}
+
+	return c.MapFS.Open(name)
+}
+
+func (c countingFS) ReadFile(name string) ([]byte, error) {
+	if counter, ok := c.opened[name]; ok {
+		atomic.AddInt32(counter, 1)
+	}
+
+	return c.MapFS.ReadFile(name)
+}
+
+func newCountingFS() countingFS {
+	files := countingFS{
+		MapFS: fstest.MapFS{
+			"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Greeting", "placeholders": ["name"], "translation": "Hello, {name}"}
+]`)},
+			"locales/ru/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Greeting", "placeholders": ["name"], "translation": "Привет, {name}"}
+]`)},
+		},
+		opened: map[string]*int32{},
+	}
+
+	for name := range files.MapFS {
+		files.opened[name] = new(int32)
+	}
+
+	return files
+}
+
+func TestLazyCatalog(t *testing.T) {
+	t.Parallel()
+
+	files := newCountingFS()
+
+	lazy, err := InitLazy(context.Background(), LazyOptions{}, files)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []language.Tag{language.English, language.Russian}, lazy.Languages())
+	assert.Zero(t, atomic.LoadInt32(files.opened["locales/en/active.json"]))
+
+	var (
+		wg       sync.WaitGroup
+		builders = make([]*catalog.Builder, 20)
+	)
+
+	for idx := range builders {
+		wg.Add(1)
+
+		go func(idx int) {
+			defer wg.Done()
+
+			builders[idx] = lazy.Builder(context.Background(), language.BritishEnglish)
+		}(idx)
+	}
+
+	wg.Wait()
+
+	for _, builder := range builders {
+		assert.Same(t, builders[0], builder)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(files.opened["locales/en/active.json"]))
+	assert.Zero(t, atomic.LoadInt32(files.opened["locales/ru/active.json"]))
+	assert.Equal(t, []language.Tag{language.English}, builders[0].Languages())
+
+	args := NamedArgs(lazy.Root(), "Greeting", map[string]string{"name": "John"})
+	printer := message.NewPrinter(language.BritishEnglish, message.Catalog(builders[0]))
+	assert.Equal(t, "Hello, John", printer.Sprintf("Greeting", args...))
+
+	changed, err := lazy.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestLazyCatalog_Evict(t *testing.T) {
+	t.Parallel()
+
+	files := newCountingFS()
+
+	lazy, err := InitLazy(context.Background(), LazyOptions{EvictAfter: 20 * time.Millisecond}, files)
+	require.NoError(t, err)
+
+	first := lazy.Builder(context.Background(), language.English)
+	assert.Same(t, first, lazy.Builder(context.Background(), language.English))
+
+	time.Sleep(50 * time.Millisecond)
+
+	lazy.Builder(context.Background(), language.Russian)
+
+	second := lazy.Builder(context.Background(), language.English)
+	assert.NotSame(t, first, second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(files.opened["locales/en/active.json"]))
+	assert.Equal(t, "Hello, John", message.NewPrinter(language.English, message.Catalog(second)).Sprintf("Greeting", "John"))
+}
+
+func TestLazyCatalog_Discovery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index":
+			_, _ = w.Write([]byte(`["en", "de"]`))
+		case "/de":
+			_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Veröffentlicht"}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	SetExternalLoader(NewExternalLoader(srv.URL, nil).WithDiscovery("index"))
+	defer SetExternalLoader(nil)
+
+	lazy, err := InitLazy(context.Background(), LazyOptions{}, localeFS(`[{"key": "Published", "translation": "Published"}]`))
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []language.Tag{language.English, language.German}, lazy.Languages())
+
+	de := lazy.Builder(context.Background(), language.German)
+	assert.Equal(t, "Veröffentlicht", message.NewPrinter(language.German, message.Catalog(de)).Sprintf("Published"))
+
+	require.NoError(t, lazy.LoadAll(context.Background()))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshot(&buf, lazy.Root(), "v1"))
+
+	restored, err := ReadSnapshot(&buf, "v1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []language.Tag{language.English, language.German}, restored.Languages())
+}
+
+func TestLazyCatalog_SQL(t *testing.T) {
+	fake := &fakeDB{}
+	fake.set(
+		[]driver.Value{"Published", "en", "Published (db)", nil},
+		[]driver.Value{"Published", "ru", "Опубликовано (бд)", nil},
+	)
+
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { _ = db.Close() })
+
+	SetExternalLoader(NewSQLLoader(db, SQLLayout{}))
+	defer SetExternalLoader(nil)
+
+	lazy, err := InitLazy(context.Background(), LazyOptions{}, newCountingFS())
+	require.NoError(t, err)
+
+	en := lazy.Builder(context.Background(), language.English)
+	ru := lazy.Builder(context.Background(), language.Russian)
+
+	// Every builder gets only its language.
+	assert.Equal(t, []language.Tag{language.English}, en.Languages())
+	assert.Equal(t, []language.Tag{language.Russian}, ru.Languages())
+	assert.Equal(t, "Published (db)", message.NewPrinter(language.English, message.Catalog(en)).Sprintf("Published"))
+
+	changed, err := lazy.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	fake.set(
+		[]driver.Value{"Published", "en", "Edited", nil},
+		[]driver.Value{"Published", "ru", "Опубликовано (бд)", nil},
+	)
+
+	changed, err = lazy.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Edited", message.NewPrinter(language.English, message.Catalog(en)).Sprintf("Published"))
+}
+
+func TestLazyCatalog_FSLoader(t *testing.T) {
+	SetExternalLoader(NewFSLoader(fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Published (fs)"}]`)},
+		"locales/ru/active.json": &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Опубликовано (fs)"}]`)},
+	}))
+	defer SetExternalLoader(nil)
+
+	lazy, err := InitLazy(context.Background(), LazyOptions{}, localeFS(`[]`))
+	require.NoError(t, err)
+
+	en := lazy.Builder(context.Background(), language.English)
+	assert.Equal(t, []language.Tag{language.English}, en.Languages())
+	assert.Equal(t, "Published (fs)", message.NewPrinter(language.English, message.Catalog(en)).Sprintf("Published"))
+}
@@ -1,10 +1,13 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"io/fs"
-	"path"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"golang.org/x/text/feature/plural"
@@ -13,9 +16,34 @@ import (
 )
 
 type Loader interface {
+	Load(ctx context.Context, cat *catalog.Builder) error
+}
+
+// ChangeLoader is a Loader able to report whether loaded translations were changed.
+type ChangeLoader interface {
+	Loader
+	LoadChanged(ctx context.Context, cat *catalog.Builder) (bool, error)
+}
+
+// LegacyLoader is a loader without context support.
+type LegacyLoader interface {
 	Load(cat *catalog.Builder) error
 }
 
+type LoaderFunc func(ctx context.Context, cat *catalog.Builder) error
+
+func (f LoaderFunc) Load(ctx context.Context, cat *catalog.Builder) error {
+	return f(ctx, cat)
+}
+
+// LoaderWithoutContext adapts the legacy loader. Messages it sets to the catalog are not tracked, so they have
+// no precedence within CompositeLoader and are not included in snapshots.
+func LoaderWithoutContext(loader LegacyLoader) Loader {
+	return LoaderFunc(func(_ context.Context, cat *catalog.Builder) error {
+		return loader.Load(cat)
+	})
+}
+
 var (
 	extendBuilder func(builder *catalog.Builder) error
 	extLoader     Loader
@@ -29,16 +57,39 @@ func SetExternalLoader(loader Loader) {
 	extLoader = loader
 }
 
-func RefreshTranslations(builder *catalog.Builder) error {
-	if extLoader == nil {
-		return nil
+// RefreshTranslations re-reads changed locale files of the builder and reloads the external loader.
+func RefreshTranslations(ctx context.Context, builder *catalog.Builder) (bool, error) {
+	var (
+		changed bool
+		errs    LoadErrors
+	)
+
+	if locales := registryOf(builder).overlay(); locales != nil {
+		var err error
+
+		if changed, err = locales.load(ctx, builder); err != nil {
+			if !IsPartial(err) {
+				return changed, errors.WithMessage(err, "reload locale files")
+			}
+
+			errs.add(err)
+		}
 	}
 
-	if err := extLoader.Load(builder); err != nil {
-		return errors.WithMessage(err, "load translations from external")
+	if extLoader != nil {
+		extChanged, err := loadChanged(ctx, extLoader, builder)
+		changed = changed || extChanged
+
+		if err != nil {
+			if !IsPartial(err) {
+				return changed, errs.abort(errors.WithMessage(err, "load translations from external"))
+			}
+
+			errs.add(err)
+		}
 	}
 
-	return nil
+	return changed, errs.errOrNil()
 }
 
 type Translation struct {
@@ -46,6 +97,205 @@ type Translation struct {
 	Description string   `json:"description"`
 	Translation string   `json:"translation"`
 	Plural      *plurals `json:"plural"`
+
+	Placeholders []string `json:"placeholders"`
+	// PluralArg names the placeholder selecting the plural form, the last placeholder if empty.
+	PluralArg string `json:"plural_arg"`
+	Digits    *int   `json:"digits"`
+
+	Plurals map[string]*nestedPlural `json:"plurals"`
+}
+
+func (t *Translation) named() bool {
+	return len(t.Placeholders) > 0
+}
+
+func (t *Translation) text(s string) (string, error) {
+	if !t.named() {
+		return s, nil
+	}
+
+	return compileNamed(s, t.Placeholders)
+}
+
+func (t *Translation) pluralArg() (int, error) {
+	if !t.named() {
+		return len(argVerbs(t.Plural.Other)), nil
+	}
+
+	if t.PluralArg == "" {
+		return len(t.Placeholders), nil
+	}
+
+	pos := indexOf(t.Placeholders, t.PluralArg)
+	if pos < 0 {
+		return 0, errors.Wrapf(ErrInvalidPlaceholder, "undeclared plural argument %q", t.PluralArg)
+	}
+
+	return pos + 1, nil
+}
+
+func (t *Translation) nestedArg(name string, nested *nestedPlural) (int, error) {
+	if nested.Arg > 0 {
+		return nested.Arg, nil
+	}
+
+	if pos := indexOf(t.Placeholders, name); pos >= 0 {
+		return pos + 1, nil
+	}
+
+	return 0, errors.Errorf("no argument for nested plural %q", name)
+}
+
+func (t *Translation) nestedNames() []string {
+	names := make([]string, 0, len(t.Plurals))
+	for name := range t.Plurals {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// selectf builds plural selection over the arg-th argument.
+func (t *Translation) selectf(arg int, digits *int, p *plurals) (catalog.Message, error) {
+	var format string
+
+	switch {
+	case digits != nil:
+		if *digits < 0 {
+			return nil, errors.Errorf("negative digits %d", *digits)
+		}
+
+		format = "%." + strconv.Itoa(*digits) + "f"
+
+	default:
+		other, err := t.text(p.Other)
+		if err != nil {
+			return nil, err
+		}
+
+		if verbs := argVerbs(other); arg > 0 && arg <= len(verbs) {
+			format = verbs[arg-1]
+		}
+	}
+
+	var err error
+
+	cases := p.cases()
+	for idx := 1; idx < len(cases); idx += 2 {
+		if cases[idx], err = t.text(cases[idx].(string)); err != nil {
+			return nil, errors.WithMessagef(err, "case %v", cases[idx-1])
+		}
+	}
+
+	return plural.Selectf(arg, format, cases...), nil
+}
+
+func (t *Translation) message() ([]catalog.Message, error) {
+	msgs := make([]catalog.Message, 0, len(t.Plurals)+1)
+
+	for _, name := range t.nestedNames() {
+		if !isIdentifier(name) {
+			return nil, errors.Errorf("bad nested plural name %q", name)
+		}
+
+		nested := t.Plurals[name]
+
+		arg, err := t.nestedArg(name, nested)
+		if err != nil {
+			return nil, err
+		}
+
+		msg, err := t.selectf(arg, nested.Digits, &nested.plurals)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "nested plural %s", name)
+		}
+
+		msgs = append(msgs, catalog.Var(name, msg))
+	}
+
+	if t.Plural == nil {
+		text, err := t.text(t.Translation)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(msgs, catalog.String(text)), nil
+	}
+
+	arg, err := t.pluralArg()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := t.selectf(arg, t.Digits, t.Plural)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(msgs, msg), nil
+}
+
+// pluralArgs returns all plural selections of the message.
+func (t *Translation) pluralArgs() []pluralMeta {
+	var args []pluralMeta
+
+	if t.Plural != nil {
+		if arg, err := t.pluralArg(); err == nil {
+			args = append(args, pluralMeta{arg: arg, digits: t.Digits})
+		}
+	}
+
+	for _, name := range t.nestedNames() {
+		if arg, err := t.nestedArg(name, t.Plurals[name]); err == nil {
+			args = append(args, pluralMeta{arg: arg, digits: t.Plurals[name].Digits})
+		}
+	}
+
+	return args
+}
+
+type nestedPlural struct {
+	Arg    int
+	Digits *int
+	plurals
+}
+
+func (n *nestedPlural) UnmarshalJSON(data []byte) error {
+	var (
+		params struct {
+			Arg    int  `json:"arg"`
+			Digits *int `json:"digits"`
+		}
+		raw map[string]json.RawMessage
+	)
+
+	if err := json.Unmarshal(data, &params); err != nil {
+		return errors.Wrap(err, "unmarshal params")
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.Wrap(err, "unmarshal cases")
+	}
+
+	delete(raw, "arg")
+	delete(raw, "digits")
+
+	cases, err := json.Marshal(raw)
+	if err != nil {
+		return errors.Wrap(err, "marshal cases")
+	}
+
+	if err := n.plurals.UnmarshalJSON(cases); err != nil {
+		return err
+	}
+
+	n.Arg = params.Arg
+	n.Digits = params.Digits
+
+	return nil
 }
 
 type pluralsBase struct {
@@ -90,6 +340,15 @@ func (p *plurals) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (p *plurals) texts() []string {
+	texts := []string{p.Zero, p.One, p.Two, p.Few, p.Many, p.Other}
+	for _, val := range p.Custom {
+		texts = append(texts, val)
+	}
+
+	return texts
+}
+
 func (p *plurals) cases() (cases []interface{}) {
 	capacity := 12
 	if len(p.Custom) > 0 {
@@ -131,109 +390,184 @@ func (p *plurals) cases() (cases []interface{}) {
 	return cases
 }
 
-func InitBuilder(fs fs.ReadDirFS) (*catalog.Builder, error) {
+// InitBuilder creates the catalog. Builder is returned along with LoadErrors if some sources failed in
+// continue on error mode.
+func InitBuilder(ctx context.Context, fs fs.ReadDirFS) (*catalog.Builder, error) {
+	return InitBuilderLayers(ctx, fs)
+}
+
+// InitBuilderLayers creates the catalog from several filesystems, messages of later layers override the same
+// keys of earlier ones. Layers are re-read by RefreshTranslations.
+func InitBuilderLayers(ctx context.Context, layers ...fs.ReadDirFS) (*catalog.Builder, error) {
+	locales := &overlay{layers: layers}
+
+	return initBuilder(ctx, func(ctx context.Context, cat *catalog.Builder) error {
+		registryOf(cat).setOverlay(locales)
+
+		_, err := locales.load(ctx, cat)
+
+		return err
+	})
+}
+
+// initBuilder creates the catalog from local translations, the external loader and the extend builder.
+func initBuilder(ctx context.Context, local LoaderFunc) (*catalog.Builder, error) {
 	cat := catalog.NewBuilder()
 
-	if err := loadTranslations(fs, cat); err != nil {
-		return nil, errors.Wrap(err, "load translations")
+	loadErr := loadTranslations(ctx, local, cat)
+	if loadErr != nil && !IsPartial(loadErr) {
+		ReleaseBuilder(cat)
+
+		return nil, errors.Wrap(loadErr, "load translations")
 	}
 
 	if extendBuilder != nil {
 		if err := extendBuilder(cat); err != nil {
+			ReleaseBuilder(cat)
+
 			return nil, errors.Wrap(err, "extend builder")
 		}
 	}
 
-	return cat, nil
+	return cat, loadErr
 }
 
-func loadTranslations(files fs.ReadDirFS, cat *catalog.Builder) error {
-	dir, err := files.ReadDir("locales")
-	if err != nil {
-		return errors.Wrap(err, "read locales dir")
-	}
-
-	for _, entry := range dir {
-		if !entry.IsDir() {
-			continue
-		}
-
-		lang, err := language.Parse(entry.Name())
-		if err != nil {
-			return errors.Wrapf(err, "parse language %s", entry.Name())
-		}
-
-		subDirPath := path.Join("locales", entry.Name())
-
-		filePath := path.Join(subDirPath, "active.json")
+func loadTranslations(ctx context.Context, local Loader, cat *catalog.Builder) error {
+	var errs LoadErrors
 
-		reader, err := files.Open(filePath)
-		if err != nil {
-			return errors.Wrapf(err, "open file %s", filePath)
+	if err := applyBundles(ctx, cat, language.Und); err != nil {
+		if !IsPartial(err) {
+			return err
 		}
 
-		if err := load(reader, lang, cat); err != nil {
-			if cErr := reader.Close(); cErr != nil {
-				_ = cErr
-			}
+		errs.add(err)
+	}
 
-			return errors.Wrapf(err, "load translations from %s", filePath)
+	if err := local.Load(ctx, cat); err != nil {
+		if !IsPartial(err) {
+			return err
 		}
 
-		if err := reader.Close(); err != nil {
-			return errors.Wrapf(err, "close file %s", filePath)
-		}
+		errs.add(err)
 	}
 
 	if extLoader != nil {
-		if err := extLoader.Load(cat); err != nil {
-			return errors.WithMessage(err, "load translations from external")
+		if err := extLoader.Load(ctx, cat); err != nil {
+			if !continueOnError || !IsPartial(err) {
+				return errs.abort(errors.WithMessage(err, "load translations from external"))
+			}
+
+			errs.add(err)
 		}
 	}
 
-	return nil
+	return errs.errOrNil()
 }
 
-func load(r io.Reader, lang language.Tag, cat *catalog.Builder) error {
+// load applies translations from r. Messages rejected by the policy are returned as ValidationError after
+// everything else is applied.
+func load(ctx context.Context, r io.Reader, lang language.Tag, cat *catalog.Builder, policy *ValidationPolicy) error {
 	var translations []Translation
 	if err := json.NewDecoder(r).Decode(&translations); err != nil {
 		return errors.Wrap(err, "decode translation")
 	}
 
+	return applyTranslations(ctx, translations, lang, cat, policy)
+}
+
+func applyTranslations(ctx context.Context, translations []Translation, lang language.Tag, cat *catalog.Builder, policy *ValidationPolicy) error {
+	reg, source := registryOf(cat), layerFrom(ctx)
+
+	if namespace := namespaceFrom(ctx); namespace != "" {
+		translations = namespaced(translations, namespace)
+	}
+
+	if tenant, ok := tenantFrom(ctx); ok {
+		lang = tenant.apply(lang, translations)
+	}
+
+	var rejected []RejectedMessage
+
+	if policy != nil {
+		var err error
+
+		if translations, rejected, err = policy.validate(reg, lang, translations); err != nil {
+			return errors.WithMessage(err, "validate translations")
+		}
+	}
+
+	changedVars, err := reg.setVars(lang, translations, source)
+	if err != nil {
+		return errors.WithMessage(err, "set variables")
+	}
+
 	for idx := range translations {
 		trans := &translations[idx]
 
-		switch {
-		case trans.Plural != nil:
-			count, format := getPlaceholders(trans.Plural.Other)
-
-			msg := plural.Selectf(count, format, trans.Plural.cases()...)
+		if trans.isVar() || trans.Plural == nil && trans.Translation == "" {
+			continue
+		}
 
-			if err := cat.Set(lang, trans.Key, msg); err != nil {
-				return errors.Wrapf(err, "set message for %s", trans.Key)
-			}
+		if err := reg.set(cat, lang, trans, source); err != nil {
+			return err
+		}
+	}
 
-		case trans.Translation != "":
-			if err := cat.Set(lang, trans.Key, catalog.String(trans.Translation)); err != nil {
-				return errors.Wrapf(err, "set string for %s", trans.Key)
-			}
+	if len(changedVars) > 0 {
+		if err := reg.recompile(cat, lang, changedVars); err != nil {
+			return errors.WithMessage(err, "recompile messages with changed variables")
 		}
 	}
 
+	if len(rejected) > 0 {
+		return &ValidationError{Language: lang, Rejected: rejected}
+	}
+
 	return nil
 }
 
-func getPlaceholders(s string) (count int, format string) {
+// argVerbs returns verbs of the arguments referenced by s, indexed by argument position.
+func argVerbs(s string) []string {
+	var (
+		verbs  []string
+		argNum int
+	)
+
 	for idx := 0; idx < len(s); idx++ {
-		if s[idx] == '%' {
-			count++
+		if s[idx] != '%' {
+			continue
+		}
 
-			if format == "" {
-				format = s[idx : idx+1]
-			}
+		start := idx
+		idx++
+
+		for idx < len(s) && strings.IndexByte("+-# 0123456789.[]", s[idx]) >= 0 {
 			idx++
 		}
+
+		if idx >= len(s) || s[idx] == '%' && idx == start+1 {
+			continue
+		}
+
+		verb := s[start : idx+1]
+
+		if open := strings.IndexByte(verb, '['); open >= 0 {
+			if end := strings.IndexByte(verb, ']'); end > open {
+				if num, err := strconv.Atoi(verb[open+1 : end]); err == nil && num > 0 {
+					argNum = num - 1
+				}
+
+				verb = verb[:open] + verb[end+1:]
+			}
+		}
+
+		for len(verbs) <= argNum {
+			verbs = append(verbs, "")
+		}
+
+		verbs[argNum] = verb
+		argNum++
 	}
 
-	return count, format
+	return verbs
 }
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+var continueOnError bool
+
+// SetContinueOnError makes loading of locale files continue past failing files.
+func SetContinueOnError(enabled bool) {
+	continueOnError = enabled
+}
+
+// LoadError is a failure of loading a single language from a single source.
+type LoadError struct {
+	Language language.Tag
+	Source   string
+	Err      error
+}
+
+func (e *LoadError) Error() string {
+	return e.Language.String() + " (" + e.Source + "): " + e.Err.Error()
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// LoadErrors aggregates failures of loading, everything else was applied.
+type LoadErrors struct {
+	Errors []*LoadError
+}
+
+func (e *LoadErrors) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return "failed to load " + strings.Join(messages, "; ")
+}
+
+func (e *LoadErrors) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *LoadErrors) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// add appends err to the list, flattening nested LoadErrors.
+func (e *LoadErrors) add(err error) {
+	var nested *LoadErrors
+	if errors.As(err, &nested) {
+		e.Errors = append(e.Errors, nested.Errors...)
+
+		return
+	}
+
+	var loadErr *LoadError
+	if errors.As(err, &loadErr) {
+		e.Errors = append(e.Errors, loadErr)
+
+		return
+	}
+
+	e.Errors = append(e.Errors, &LoadError{Language: language.Und, Err: err})
+}
+
+func (e *LoadErrors) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// abort returns err which stopped the load along with failures collected before it, err if there are none.
+func (e *LoadErrors) abort(err error) error {
+	if len(e.Errors) == 0 {
+		return err
+	}
+
+	return &abortedLoad{err: err, collected: e}
+}
+
+// abortedLoad is a failure which stopped the load, errors.Is and errors.As also see failures collected before it.
+// It is partial only if the failure itself is.
+type abortedLoad struct {
+	err       error
+	collected *LoadErrors
+}
+
+func (e *abortedLoad) Error() string {
+	return e.err.Error() + ", previously " + e.collected.Error()
+}
+
+func (e *abortedLoad) Unwrap() error {
+	return e.err
+}
+
+func (e *abortedLoad) Is(target error) bool {
+	return e.collected.Is(target)
+}
+
+func (e *abortedLoad) As(target interface{}) bool {
+	return e.collected.As(target)
+}
+
+// IsPartial reports whether err only describes failed parts of otherwise completed load.
+func IsPartial(err error) bool {
+	var loadErrs *LoadErrors
+
+	return errors.As(err, &loadErrs)
+}
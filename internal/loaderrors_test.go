@@ -0,0 +1,103 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestContinueOnError(t *testing.T) {
+	SetContinueOnError(true)
+	defer SetContinueOnError(false)
+
+	builder, err := InitBuilder(context.Background(), fstest.MapFS{
+		"locales/en/active.json":    &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Published"}]`)},
+		"locales/de/active.json":    &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Veröffentlicht"`)},
+		"locales/fr/active.json":    &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Publié"}]`)},
+		"locales/nope!/active.json": &fstest.MapFile{Data: []byte(`[]`)},
+	})
+	require.Error(t, err)
+	require.NotNil(t, builder)
+
+	var loadErrs *LoadErrors
+	require.True(t, errors.As(err, &loadErrs))
+	require.Len(t, loadErrs.Errors, 2)
+
+	assert.Equal(t, language.German, loadErrs.Errors[0].Language)
+	assert.Equal(t, "locales/de/active.json", loadErrs.Errors[0].Source)
+	assert.Equal(t, "locales/nope!/active.json", loadErrs.Errors[1].Source)
+
+	assert.ElementsMatch(t, []language.Tag{language.English, language.French}, builder.Languages())
+	assert.Equal(t, "Publié", message.NewPrinter(language.French, message.Catalog(builder)).Sprintf("Published"))
+}
+
+func TestExternalLoader_ContinueOnError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/en":
+			_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Remote"}]`))
+		case "/fr":
+			_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Distant"}]`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	files := fstest.MapFS{}
+	for _, lang := range []string{"en", "de", "fr"} {
+		files["locales/"+lang+"/active.json"] = &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Embedded"}]`)}
+	}
+
+	builder, err := InitBuilder(context.Background(), files)
+	require.NoError(t, err)
+
+	err = NewExternalLoader(srv.URL, nil).WithContinueOnError(true).Load(context.Background(), builder)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidResponseCode))
+
+	var loadErrs *LoadErrors
+	require.True(t, errors.As(err, &loadErrs))
+	require.Len(t, loadErrs.Errors, 1)
+	assert.Equal(t, language.German, loadErrs.Errors[0].Language)
+	assert.Equal(t, srv.URL+"/de", loadErrs.Errors[0].Source)
+
+	assert.Equal(t, "Remote", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+	assert.Equal(t, "Embedded", message.NewPrinter(language.German, message.Catalog(builder)).Sprintf("Published"))
+	assert.Equal(t, "Distant", message.NewPrinter(language.French, message.Catalog(builder)).Sprintf("Published"))
+}
+
+func TestContinueOnError_ExternalFailed(t *testing.T) {
+	SetContinueOnError(true)
+	defer SetContinueOnError(false)
+
+	externalErr := errors.New("external is down")
+
+	SetExternalLoader(LoaderFunc(func(context.Context, *catalog.Builder) error {
+		return externalErr
+	}))
+	defer SetExternalLoader(nil)
+
+	builder, err := InitBuilder(context.Background(), fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Published"}]`)},
+		"locales/de/active.json": &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Veröffentlicht"`)},
+	})
+	require.Error(t, err)
+	assert.Nil(t, builder)
+	assert.False(t, IsPartial(err))
+	assert.True(t, errors.Is(err, externalErr))
+	assert.Contains(t, err.Error(), "locales/de/active.json")
+}
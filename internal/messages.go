@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// Locale is a list of messages of a language in a form usable in Go source, see cmd/goi18n-gen.
+type Locale struct {
+	Language string
+	Messages []Message
+}
+
+// Message is a translation of a locale file. Plural cases are keyed by their names or selectors, e.g. "=0".
+type Message struct {
+	Key          string
+	Description  string
+	Translation  string
+	Plural       map[string]string
+	Placeholders []string
+	// PluralArg names the placeholder selecting the plural form, the last placeholder if empty.
+	PluralArg string
+	Digits    *int
+	Plurals   map[string]NestedPlural
+}
+
+type NestedPlural struct {
+	Arg    int
+	Digits *int
+	Cases  map[string]string
+}
+
+// ReadLocales reads locale files matching the layout patterns, default layout is used without patterns.
+func ReadLocales(files fs.ReadDirFS, patterns ...string) ([]Locale, error) {
+	if len(patterns) == 0 {
+		patterns = layout
+	}
+
+	if err := validateLayout(patterns); err != nil {
+		return nil, err
+	}
+
+	read, err := readLocales(files, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	locales := make([]Locale, 0, len(read))
+
+	for _, file := range read {
+		if file.err != nil {
+			return nil, file.err
+		}
+
+		var translations []Translation
+		if err := json.NewDecoder(bytes.NewReader(file.data)).Decode(&translations); err != nil {
+			return nil, &LoadError{Language: file.lang, Source: file.path, Err: errors.Wrap(err, "decode translation")}
+		}
+
+		locale := Locale{Language: file.lang.String(), Messages: make([]Message, 0, len(translations))}
+		for idx := range translations {
+			locale.Messages = append(locale.Messages, messageOf(&translations[idx]))
+		}
+
+		locales = append(locales, locale)
+	}
+
+	return locales, nil
+}
+
+// InitBuilderLocales creates the catalog from locales the same way InitBuilder does from locale files.
+func InitBuilderLocales(ctx context.Context, locales []Locale) (*catalog.Builder, error) {
+	return initBuilder(withLayer(ctx, layer{rank: -1}), func(ctx context.Context, cat *catalog.Builder) error {
+		var errs LoadErrors
+
+		locales = append([]Locale(nil), locales...)
+		sort.SliceStable(locales, func(i, j int) bool {
+			return validationPolicy.isReference(language.Make(locales[i].Language)) && !validationPolicy.isReference(language.Make(locales[j].Language))
+		})
+
+		for _, locale := range locales {
+			if err := applyLocale(ctx, locale, cat); err != nil {
+				var invalid *ValidationError
+				if !continueOnError && !errors.As(err, &invalid) {
+					return err
+				}
+
+				errs.add(err)
+			}
+		}
+
+		return errs.errOrNil()
+	})
+}
+
+func applyLocale(ctx context.Context, locale Locale, cat *catalog.Builder) error {
+	lang, err := language.Parse(locale.Language)
+	if err != nil {
+		return &LoadError{Language: language.Und, Source: "generated", Err: errors.Wrapf(err, "parse language %s", locale.Language)}
+	}
+
+	translations := make([]Translation, 0, len(locale.Messages))
+	for idx := range locale.Messages {
+		translations = append(translations, *locale.Messages[idx].translation())
+	}
+
+	if err := applyTranslations(ctx, translations, lang, cat, validationPolicy); err != nil {
+		return &LoadError{Language: lang, Source: "generated", Err: err}
+	}
+
+	return nil
+}
+
+func messageOf(trans *Translation) Message {
+	msg := Message{
+		Key:          trans.Key,
+		Description:  trans.Description,
+		Translation:  trans.Translation,
+		Placeholders: trans.Placeholders,
+		PluralArg:    trans.PluralArg,
+		Digits:       trans.Digits,
+	}
+
+	if trans.Plural != nil {
+		msg.Plural = trans.Plural.toMap()
+	}
+
+	if len(trans.Plurals) > 0 {
+		msg.Plurals = make(map[string]NestedPlural, len(trans.Plurals))
+
+		for name, nested := range trans.Plurals {
+			msg.Plurals[name] = NestedPlural{Arg: nested.Arg, Digits: nested.Digits, Cases: nested.plurals.toMap()}
+		}
+	}
+
+	return msg
+}
+
+func (m *Message) translation() *Translation {
+	trans := &Translation{
+		Key:          m.Key,
+		Description:  m.Description,
+		Translation:  m.Translation,
+		Placeholders: m.Placeholders,
+		PluralArg:    m.PluralArg,
+		Digits:       m.Digits,
+	}
+
+	if m.Plural != nil {
+		trans.Plural = pluralsFromMap(m.Plural)
+	}
+
+	if len(m.Plurals) > 0 {
+		trans.Plurals = make(map[string]*nestedPlural, len(m.Plurals))
+
+		for name, nested := range m.Plurals {
+			trans.Plurals[name] = &nestedPlural{Arg: nested.Arg, Digits: nested.Digits, plurals: *pluralsFromMap(nested.Cases)}
+		}
+	}
+
+	return trans
+}
+
+func (p *plurals) toMap() map[string]string {
+	cases := make(map[string]string, 6+len(p.Custom))
+
+	for cond, text := range p.Custom {
+		cases[cond] = text
+	}
+
+	for cond, text := range map[string]string{
+		"zero": p.Zero, "one": p.One, "two": p.Two, "few": p.Few, "many": p.Many, "other": p.Other,
+	} {
+		if text != "" {
+			cases[cond] = text
+		}
+	}
+
+	return cases
+}
+
+func pluralsFromMap(cases map[string]string) *plurals {
+	p := &plurals{Custom: map[string]string{}}
+
+	for cond, text := range cases {
+		switch cond {
+		case "zero":
+			p.Zero = text
+		case "one":
+			p.One = text
+		case "two":
+			p.Two = text
+		case "few":
+			p.Few = text
+		case "many":
+			p.Many = text
+		case "other":
+			p.Other = text
+		default:
+			p.Custom[cond] = text
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/message/catalog"
+)
+
+var ErrInvalidPlaceholder = errors.New("invalid placeholder")
+
+const namedTag = "i18n"
+
+func validatePlaceholders(names []string) error {
+	seen := make(map[string]struct{}, len(names))
+
+	for _, name := range names {
+		if !isIdentifier(name) {
+			return errors.Wrapf(ErrInvalidPlaceholder, "bad placeholder name %q", name)
+		}
+
+		if _, ok := seen[name]; ok {
+			return errors.Wrapf(ErrInvalidPlaceholder, "duplicate placeholder %q", name)
+		}
+
+		seen[name] = struct{}{}
+	}
+
+	return nil
+}
+
+// compileNamed turns {name} placeholders into positional verbs according to the names order. A placeholder
+// may set the verb, e.g. {from:s} or {weight:.1f}, %v is used otherwise.
+func compileNamed(text string, names []string) (string, error) {
+	var out strings.Builder
+
+	out.Grow(len(text) + len(names)*4)
+
+	for idx := 0; idx < len(text); idx++ {
+		switch c := text[idx]; c {
+		case '%':
+			out.WriteString("%%")
+
+		case '$':
+			end := strings.IndexByte(text[idx:], '}')
+			if idx+1 >= len(text) || text[idx+1] != '{' || end < 0 {
+				out.WriteByte(c)
+
+				continue
+			}
+
+			out.WriteString(text[idx : idx+end+1])
+			idx += end
+
+		case '{':
+			if idx+1 < len(text) && text[idx+1] == '{' {
+				out.WriteByte('{')
+				idx++
+
+				continue
+			}
+
+			end := strings.IndexByte(text[idx:], '}')
+			if end < 0 {
+				return "", errors.Wrapf(ErrInvalidPlaceholder, "unclosed placeholder at %d", idx)
+			}
+
+			name, verb, _ := strings.Cut(text[idx+1:idx+end], ":")
+
+			pos := indexOf(names, name)
+			if pos < 0 {
+				return "", errors.Wrapf(ErrInvalidPlaceholder, "undeclared placeholder %q", name)
+			}
+
+			if verb == "" {
+				verb = "v"
+			} else if !isVerb(verb) {
+				return "", errors.Wrapf(ErrInvalidPlaceholder, "bad verb %q of placeholder %q", verb, name)
+			}
+
+			// The argument index goes right before the verb letter, after flags, width and precision.
+			out.WriteString("%" + verb[:len(verb)-1] + "[" + strconv.Itoa(pos+1) + "]" + verb[len(verb)-1:])
+			idx += end
+
+		case '}':
+			if idx+1 < len(text) && text[idx+1] == '}' {
+				idx++
+			}
+
+			out.WriteByte('}')
+
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String(), nil
+}
+
+// isVerb reports whether s is a verb with optional flags, width and precision, e.g. "s" or "-5.2f".
+func isVerb(s string) bool {
+	if last := s[len(s)-1]; !(last >= 'a' && last <= 'z' || last >= 'A' && last <= 'Z') {
+		return false
+	}
+
+	for idx := 0; idx < len(s)-1; idx++ {
+		if strings.IndexByte("+-# 0123456789.", s[idx]) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func NamedArgs(builder *catalog.Builder, key string, params interface{}) []interface{} {
+	placeholders := placeholdersOf(builder, key)
+	if len(placeholders) == 0 {
+		return nil
+	}
+
+	lookup := namedLookup(params)
+	args := make([]interface{}, len(placeholders))
+
+	for idx, name := range placeholders {
+		if val, ok := lookup(name); ok {
+			args[idx] = val
+		} else {
+			args[idx] = "{" + name + "}"
+		}
+	}
+
+	return args
+}
+
+func namedLookup(params interface{}) func(name string) (interface{}, bool) {
+	val := reflect.ValueOf(params)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			break
+		}
+
+		return func(name string) (interface{}, bool) {
+			item := val.MapIndex(reflect.ValueOf(name).Convert(val.Type().Key()))
+			if !item.IsValid() {
+				return nil, false
+			}
+
+			return item.Interface(), true
+		}
+
+	case reflect.Struct:
+		return func(name string) (interface{}, bool) {
+			field, ok := structField(val.Type(), name)
+			if !ok {
+				return nil, false
+			}
+
+			return val.FieldByIndex(field.Index).Interface(), true
+		}
+	}
+
+	return func(string) (interface{}, bool) { return nil, false }
+}
+
+func structField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	var fallback *reflect.StructField
+
+	for idx := 0; idx < typ.NumField(); idx++ {
+		field := typ.Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, _, _ := strings.Cut(field.Tag.Get(namedTag), ",")
+		switch {
+		case tag == name:
+			return field, true
+		case tag == "" && fallback == nil && strings.EqualFold(field.Name, name):
+			fallback = &field
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+
+	return reflect.StructField{}, false
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for idx, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case idx > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func indexOf(items []string, item string) int {
+	for idx := range items {
+		if items[idx] == item {
+			return idx
+		}
+	}
+
+	return -1
+}
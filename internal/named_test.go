@@ -0,0 +1,113 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func localeFS(data string) fstest.MapFS {
+	return fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(data)},
+	}
+}
+
+func TestNamedPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	t.Run("compiled", func(t *testing.T) {
+		t.Parallel()
+
+		builder, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "discount", "placeholders": ["name", "value"], "translation": "{{{name}}} gets {value}% off"},
+  {"key": "files", "placeholders": ["owner", "count"], "plural": {"one": "{owner} has one file", "other": "{owner} has {count} files"}}
+]`))
+		require.NoError(t, err)
+
+		printer := message.NewPrinter(language.English, message.Catalog(builder))
+
+		args := NamedArgs(builder, "discount", map[string]interface{}{"value": 10, "name": "Bob"})
+		assert.Equal(t, "{Bob} gets 10% off", printer.Sprintf("discount", args...))
+
+		assert.Equal(t, "Bob has one file", printer.Sprintf("files", NamedArgs(builder, "files", map[string]interface{}{"owner": "Bob", "count": 1})...))
+		assert.Equal(t, "Bob has 3 files", printer.Sprintf("files", NamedArgs(builder, "files", map[string]interface{}{"owner": "Bob", "count": 3})...))
+	})
+
+	t.Run("verbs", func(t *testing.T) {
+		t.Parallel()
+
+		builder, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "weight", "placeholders": ["name", "weight"], "translation": "{name:q} weighs {weight:.1f} kg"}
+]`))
+		require.NoError(t, err)
+
+		printer := message.NewPrinter(language.English, message.Catalog(builder))
+		args := NamedArgs(builder, "weight", map[string]interface{}{"name": "Bob", "weight": 80.25})
+		assert.Equal(t, `"Bob" weighs 80.2 kg`, printer.Sprintf("weight", args...))
+
+		_, err = InitBuilder(context.Background(), localeFS(`[{"key": "k", "placeholders": ["from"], "translation": "{from:%s}"}]`))
+		assert.True(t, errors.Is(err, ErrInvalidPlaceholder))
+	})
+
+	t.Run("undeclared", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := InitBuilder(context.Background(), localeFS(`[{"key": "k", "placeholders": ["from"], "translation": "{from} -> {to}"}]`))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidPlaceholder))
+	})
+
+	t.Run("unclosed", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := InitBuilder(context.Background(), localeFS(`[{"key": "k", "placeholders": ["from"], "translation": "{from"}]`))
+		assert.True(t, errors.Is(err, ErrInvalidPlaceholder))
+	})
+
+	t.Run("reloaded", func(t *testing.T) {
+		t.Parallel()
+
+		builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "k", "placeholders": ["from", "to"], "translation": "{from} -> {to}"}]`))
+		require.NoError(t, err)
+
+		reloaded := localeFS(`[{"key": "k", "placeholders": ["to", "from"], "translation": "{from} -> {to}"}]`)
+		require.NoError(t, NewFSLoader(reloaded).Load(context.Background(), builder))
+
+		printer := message.NewPrinter(language.English, message.Catalog(builder))
+		args := NamedArgs(builder, "k", map[string]interface{}{"from": "a", "to": "b"})
+		assert.Equal(t, "a -> b", printer.Sprintf("k", args...))
+	})
+
+	t.Run("bad name", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := InitBuilder(context.Background(), localeFS(`[{"key": "k", "placeholders": ["from", "from"], "translation": "{from}"}]`))
+		assert.True(t, errors.Is(err, ErrInvalidPlaceholder))
+	})
+}
+
+func TestReleaseBuilder(t *testing.T) {
+	t.Parallel()
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "Greeting", "placeholders": ["name"], "translation": "Hello, {name}"}
+]`))
+	require.NoError(t, err)
+
+	require.Len(t, NamedArgs(builder, "Greeting", map[string]string{"name": "John"}), 1)
+
+	ReleaseBuilder(builder)
+
+	assert.Nil(t, NamedArgs(builder, "Greeting", map[string]string{"name": "John"}))
+
+	_, ok := MessageSource(builder, language.English, "Greeting")
+	assert.False(t, ok)
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// localeFile is a read locale file of a single language, err is set if the file can not be read.
+type localeFile struct {
+	lang language.Tag
+	path string
+	data []byte
+	err  error
+}
+
+// overlay is a stack of filesystems with locale files, later layers override keys of earlier ones. Messages of
+// the overlay have lower precedence than messages of loaders.
+type overlay struct {
+	layers []fs.ReadDirFS
+
+	mu          sync.Mutex
+	loaded      bool
+	fingerprint [sha256.Size]byte
+}
+
+// load reads all layers and applies them unless files are the same as on the previous load. Files are
+// re-opened on every load, so atomically swapped symlinks (e.g. ..data of Kubernetes volumes) are followed.
+// On reload a key removed from a layer falls back to lower layers.
+func (o *overlay) load(ctx context.Context, cat *catalog.Builder) (bool, error) {
+	var (
+		errs   LoadErrors
+		hash   = sha256.New()
+		layers = make([][]localeFile, len(o.layers))
+	)
+
+	for idx, files := range o.layers {
+		locales, err := readLocales(files, layout)
+		if err != nil {
+			return false, errors.WithMessagef(err, "read layer %d", idx)
+		}
+
+		hash.Write([]byte(strconv.Itoa(idx)))
+
+		for _, locale := range locales {
+			hash.Write([]byte{0})
+			hash.Write([]byte(locale.path))
+			hash.Write([]byte{0})
+			hash.Write(locale.data)
+		}
+
+		layers[idx] = locales
+	}
+
+	var fingerprint [sha256.Size]byte
+
+	copy(fingerprint[:], hash.Sum(nil))
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.loaded && o.fingerprint == fingerprint {
+		return false, errs.errOrNil()
+	}
+
+	if o.loaded {
+		registryOf(cat).downgrade(-len(layers), -1)
+	}
+
+	// The reference language of all layers goes first, so other languages are validated against its new messages.
+	for _, reference := range []bool{true, false} {
+		for idx, locales := range layers {
+			layerCtx := withLayer(ctx, layer{rank: idx - len(layers)})
+
+			if err := applyLocales(layerCtx, referenceLocales(locales, reference), cat, &errs); err != nil {
+				return true, err
+			}
+		}
+	}
+
+	o.loaded, o.fingerprint = true, fingerprint
+
+	return true, errs.errOrNil()
+}
+
+// loadLocales loads locale files of the filesystem matching the layout patterns, only of languages the context
+// is restricted to in lazy mode.
+func loadLocales(ctx context.Context, files fs.ReadDirFS, patterns []string, cat *catalog.Builder) error {
+	var errs LoadErrors
+
+	locales, err := readLocales(files, patterns)
+	if err != nil {
+		return err
+	}
+
+	selected := locales[:0]
+
+	for _, locale := range locales {
+		if locale.lang == language.Und || selectedLanguage(ctx, locale.lang) {
+			selected = append(selected, locale)
+		}
+	}
+
+	locales = selected
+
+	locales = append(referenceLocales(locales, true), referenceLocales(locales, false)...)
+
+	if err := applyLocales(ctx, locales, cat, &errs); err != nil {
+		return err
+	}
+
+	return errs.errOrNil()
+}
+
+// referenceLocales returns files of the reference language of the validation policy or files of other languages.
+func referenceLocales(locales []localeFile, reference bool) []localeFile {
+	selected := make([]localeFile, 0, len(locales))
+
+	for _, locale := range locales {
+		if validationPolicy.isReference(locale.lang) == reference {
+			selected = append(selected, locale)
+		}
+	}
+
+	return selected
+}
+
+// readLocales reads locale files matching the layout patterns. Failed files are kept in continue on error mode.
+func readLocales(files fs.ReadDirFS, patterns []string) ([]localeFile, error) {
+	var (
+		locales []localeFile
+		seen    = map[string]struct{}{}
+	)
+
+	found, err := findLayout(files, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range found {
+		if _, ok := seen[item.path]; ok {
+			continue
+		}
+
+		seen[item.path] = struct{}{}
+
+		locale, err := readLocale(files, item)
+		if err != nil {
+			if !continueOnError {
+				return nil, err
+			}
+
+			locale.err = err
+		}
+
+		locales = append(locales, locale)
+	}
+
+	return locales, nil
+}
+
+func readLocale(files fs.ReadDirFS, item localePath) (localeFile, error) {
+	lang, err := language.Parse(item.name)
+	if err != nil {
+		return localeFile{path: item.path}, &LoadError{Language: language.Und, Source: item.path, Err: errors.Wrapf(err, "parse language %s", item.name)}
+	}
+
+	data, err := fs.ReadFile(files, item.path)
+	if err != nil {
+		return localeFile{lang: lang, path: item.path}, &LoadError{Language: lang, Source: item.path, Err: errors.Wrapf(err, "read file %s", item.path)}
+	}
+
+	return localeFile{lang: lang, path: item.path, data: data}, nil
+}
+
+// applyLocales applies read files, rejected messages and failures in continue on error mode are added to errs.
+func applyLocales(ctx context.Context, locales []localeFile, cat *catalog.Builder, errs *LoadErrors) error {
+	for _, locale := range locales {
+		if locale.err != nil {
+			errs.add(locale.err)
+
+			continue
+		}
+
+		err := load(ctx, bytes.NewReader(locale.data), locale.lang, cat, validationPolicy)
+		if err == nil {
+			continue
+		}
+
+		err = &LoadError{Language: locale.lang, Source: locale.path, Err: errors.Wrapf(err, "load translations from %s", locale.path)}
+
+		var invalid *ValidationError
+		if !continueOnError && !errors.As(err, &invalid) {
+			return err
+		}
+
+		errs.add(err)
+	}
+
+	return nil
+}
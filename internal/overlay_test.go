@@ -0,0 +1,103 @@
+package internal_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+// writeVolume emulates Kubernetes volume update: files are written into a new hidden dir and ..data symlink
+// is atomically swapped to it.
+func writeVolume(t *testing.T, dir, version, data string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, version, "en"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, version, "en", "active.json"), []byte(data), 0o600))
+
+	tmp := filepath.Join(dir, "..data_tmp")
+	require.NoError(t, os.Symlink(version, tmp))
+	require.NoError(t, os.Rename(tmp, filepath.Join(dir, "..data")))
+}
+
+func TestInitBuilderLayers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	volume := filepath.Join(dir, "volume")
+
+	writeVolume(t, volume, "..v1", `[{"key": "Published", "translation": "Disk v1"}]`)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "locales", ".tmp"), 0o755))
+	require.NoError(t, os.Symlink(filepath.Join("..", "volume", "..data", "en"), filepath.Join(dir, "locales", "en")))
+
+	disk, ok := os.DirFS(dir).(fs.ReadDirFS)
+	require.True(t, ok)
+
+	builder, err := InitBuilderLayers(context.Background(), localeFS(`[
+  {"key": "Published", "translation": "Embedded"},
+  {"key": "Title", "translation": "Embedded title"}
+]`), disk)
+	require.NoError(t, err)
+
+	printer := message.NewPrinter(language.English, message.Catalog(builder))
+	assert.Equal(t, "Disk v1", printer.Sprintf("Published"))
+	assert.Equal(t, "Embedded title", printer.Sprintf("Title"))
+
+	changed, err := RefreshTranslations(context.Background(), builder)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	writeVolume(t, volume, "..v2", `[{"key": "Published", "translation": "Disk v2"}]`)
+
+	changed, err = RefreshTranslations(context.Background(), builder)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.Equal(t, "Disk v2", printer.Sprintf("Published"))
+	assert.Equal(t, "Embedded title", printer.Sprintf("Title"))
+}
+
+func TestInitBuilderLayers_RemovedKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "locales", "en"), 0o755))
+
+	file := filepath.Join(dir, "locales", "en", "active.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[
+  {"key": "$brand", "translation": "Initech"},
+  {"key": "Published", "translation": "Override"},
+  {"key": "Title", "translation": "${brand} title"}
+]`), 0o600))
+
+	disk, ok := os.DirFS(dir).(fs.ReadDirFS)
+	require.True(t, ok)
+
+	builder, err := InitBuilderLayers(context.Background(), localeFS(`[
+  {"key": "$brand", "translation": "Acme"},
+  {"key": "Published", "translation": "Embedded"}
+]`), disk)
+	require.NoError(t, err)
+
+	printer := message.NewPrinter(language.English, message.Catalog(builder))
+	assert.Equal(t, "Override", printer.Sprintf("Published"))
+	assert.Equal(t, "Initech title", printer.Sprintf("Title"))
+
+	require.NoError(t, os.WriteFile(file, []byte(`[{"key": "Title", "translation": "${brand} title"}]`), 0o600))
+
+	changed, err := RefreshTranslations(context.Background(), builder)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.Equal(t, "Embedded", printer.Sprintf("Published"))
+	assert.Equal(t, "Acme title", printer.Sprintf("Title"))
+}
@@ -0,0 +1,73 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestExternalLoader_Parallelism(t *testing.T) {
+	t.Parallel()
+
+	languages := []string{"en", "ru", "de", "fr", "es", "it"}
+
+	files := fstest.MapFS{}
+	for _, lang := range languages {
+		files["locales/"+lang+"/active.json"] = &fstest.MapFile{Data: []byte(`[{"key": "Published", "translation": "Published"}]`)}
+	}
+
+	var current, maxCurrent int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+
+		for {
+			seen := atomic.LoadInt32(&maxCurrent)
+			if now <= seen || atomic.CompareAndSwapInt32(&maxCurrent, seen, now) {
+				break
+			}
+		}
+
+		time.Sleep(30 * time.Millisecond)
+
+		_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Published` + r.URL.Path + `"}]`))
+	}))
+	defer srv.Close()
+
+	builder, err := InitBuilder(context.Background(), files)
+	require.NoError(t, err)
+
+	loader := NewExternalLoaderWithClient(srv.URL, nil, srv.Client()).WithParallelism(3)
+
+	changed, err := loader.LoadChanged(context.Background(), builder)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&maxCurrent))
+
+	stats := loader.LastStats()
+	require.Len(t, stats, len(languages))
+
+	for _, stat := range stats {
+		assert.NoError(t, stat.Err)
+		assert.True(t, stat.Changed)
+		assert.Equal(t, 1, stat.Attempts)
+		assert.GreaterOrEqual(t, stat.Duration, 30*time.Millisecond)
+
+		translated := message.NewPrinter(stat.Language, message.Catalog(builder)).Sprintf("Published")
+		assert.Equal(t, "Published/"+stat.Language.String(), translated)
+	}
+
+	assert.Equal(t, "Published/de", message.NewPrinter(language.German, message.Catalog(builder)).Sprintf("Published"))
+}
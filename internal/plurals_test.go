@@ -0,0 +1,74 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestNestedPlurals(t *testing.T) {
+	t.Parallel()
+
+	builder, err := InitBuilder(context.Background(), fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "$unit", "translation": "item"},
+  {
+    "key": "files in folders",
+    "translation": "${files} in ${folders}",
+    "plurals": {
+      "files": {"arg": 1, "=0": "no files", "one": "%[1]d file", "other": "%[1]d files"},
+      "folders": {"arg": 2, "one": "%[2]d folder", "other": "%[2]d folders"}
+    }
+  },
+  {
+    "key": "weight of items",
+    "placeholders": ["items", "kg"],
+    "translation": "${items} weigh ${kg}",
+    "plurals": {
+      "items": {"one": "{items} ${unit}", "other": "{items} ${unit}s"},
+      "kg": {"digits": 1, "one": "{kg} kilogram", "other": "{kg} kilograms"}
+    }
+  }
+]`)},
+		"locales/ru/active.json": &fstest.MapFile{Data: []byte(`[
+  {
+    "key": "files in folders",
+    "translation": "${files} в ${folders}",
+    "plurals": {
+      "files": {"arg": 1, "one": "%[1]d файл", "few": "%[1]d файла", "many": "%[1]d файлов", "other": "%[1]d файла"},
+      "folders": {"arg": 2, "one": "%[2]d папке", "other": "%[2]d папках"}
+    }
+  }
+]`)},
+	})
+	require.NoError(t, err)
+
+	en := message.NewPrinter(language.English, message.Catalog(builder))
+	ru := message.NewPrinter(language.Russian, message.Catalog(builder))
+
+	assert.Equal(t, "1 file in 2 folders", en.Sprintf("files in folders", 1, 2))
+	assert.Equal(t, "3 files in 1 folder", en.Sprintf("files in folders", 3, 1))
+	assert.Equal(t, "no files in 1 folder", en.Sprintf("files in folders", 0, 1))
+	assert.Equal(t, "3 файла в 1 папке", ru.Sprintf("files in folders", 3, 1))
+	assert.Equal(t, "5 файлов в 21 папке", ru.Sprintf("files in folders", 5, 21))
+
+	args := PrepareArgs(builder, language.English, "weight of items", NamedArgs(builder, "weight of items", map[string]interface{}{"items": 1, "kg": 1}))
+	assert.Equal(t, "1 item weigh 1.0 kilograms", en.Sprintf("weight of items", args...))
+
+	args = PrepareArgs(builder, language.English, "weight of items", NamedArgs(builder, "weight of items", map[string]interface{}{"items": 2, "kg": "0.5"}))
+	assert.Equal(t, "2 items weigh 0.5 kilograms", en.Sprintf("weight of items", args...))
+
+	t.Run("missing arg", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := InitBuilder(context.Background(), localeFS(`[{"key": "k", "translation": "${a}", "plurals": {"a": {"one": "x", "other": "y"}}}]`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,402 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+var (
+	registriesMu sync.Mutex
+	registries   = map[*catalog.Builder]*registry{}
+)
+
+type keyMeta struct {
+	placeholders []string
+	// plurals are kept per language, digits and plural argument may differ between translations.
+	plurals map[language.Tag][]pluralMeta
+}
+
+type pluralMeta struct {
+	arg    int
+	digits *int
+}
+
+// record is an applied message along with the layer which supplied it.
+type record struct {
+	trans *Translation
+	layer layer
+}
+
+type registry struct {
+	mu        sync.RWMutex
+	meta      map[string]*keyMeta
+	vars      map[language.Tag]map[string]string
+	varLayers map[language.Tag]map[string]layer
+	records   map[language.Tag]map[string]record
+	locales   *overlay
+	// fingerprints are fingerprints of languages applied by loaders, e.g. SQLLoader, to skip unchanged ones.
+	fingerprints map[fingerprintKey][sha256.Size]byte
+}
+
+type fingerprintKey struct {
+	loader interface{}
+	lang   language.Tag
+}
+
+func newRegistry() *registry {
+	return &registry{
+		meta:      map[string]*keyMeta{},
+		vars:      map[language.Tag]map[string]string{},
+		varLayers: map[language.Tag]map[string]layer{},
+		records:   map[language.Tag]map[string]record{},
+
+		fingerprints: map[fingerprintKey][sha256.Size]byte{},
+	}
+}
+
+// registryOf returns registry of the builder, creating it on first use. The registry is kept until
+// ReleaseBuilder is called.
+func registryOf(builder *catalog.Builder) *registry {
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+
+	reg, ok := registries[builder]
+	if !ok {
+		reg = newRegistry()
+		registries[builder] = reg
+	}
+
+	return reg
+}
+
+// lookupRegistry returns registry of the builder without creating it, so reading unknown builders does not
+// keep them alive.
+func lookupRegistry(builder *catalog.Builder) (*registry, bool) {
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+
+	reg, ok := registries[builder]
+
+	return reg, ok
+}
+
+// shareRegistry makes builder use registry of the root builder.
+func shareRegistry(builder, root *catalog.Builder) {
+	reg := registryOf(root)
+
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+
+	registries[builder] = reg
+}
+
+// ReleaseBuilder drops metadata kept for the builder, e.g. placeholders and the origin of messages, so the
+// builder can be garbage collected. The builder must not be used with this package afterwards.
+func ReleaseBuilder(builder *catalog.Builder) {
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+
+	delete(registries, builder)
+}
+
+// forget drops messages and variables of the language.
+func (r *registry) forget(lang language.Tag) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, lang)
+	delete(r.vars, lang)
+	delete(r.varLayers, lang)
+
+	for _, meta := range r.meta {
+		delete(meta.plurals, lang)
+	}
+
+	for key := range r.fingerprints {
+		if key.lang == lang {
+			delete(r.fingerprints, key)
+		}
+	}
+}
+
+// fingerprint returns the fingerprint of the language last applied by the loader.
+func (r *registry) fingerprint(loader interface{}, lang language.Tag) [sha256.Size]byte {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.fingerprints[fingerprintKey{loader: loader, lang: lang}]
+}
+
+// forgetFingerprints makes the loader apply all languages on the next load.
+func (r *registry) forgetFingerprints(loader interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key := range r.fingerprints {
+		if key.loader == loader {
+			delete(r.fingerprints, key)
+		}
+	}
+}
+
+func (r *registry) setFingerprint(loader interface{}, lang language.Tag, fingerprint [sha256.Size]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fingerprints[fingerprintKey{loader: loader, lang: lang}] = fingerprint
+}
+
+// downgrade moves messages and variables of layers ranked from..to below these layers, so on reload keys no
+// longer supplied by a layer are taken from lower layers, a key supplied by no layer keeps its last message.
+// All languages are affected if none are given.
+func (r *registry) downgrade(from, to int, languages ...language.Tag) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	affected := func(lang language.Tag) bool {
+		return len(languages) == 0 || indexOfTag(languages, lang) >= 0
+	}
+
+	for lang, records := range r.records {
+		if !affected(lang) {
+			continue
+		}
+
+		for key, rec := range records {
+			if rec.layer.rank >= from && rec.layer.rank <= to {
+				rec.layer = layer{name: rec.layer.name, rank: from - 1}
+				records[key] = rec
+			}
+		}
+	}
+
+	for lang, sources := range r.varLayers {
+		if !affected(lang) {
+			continue
+		}
+
+		for name, source := range sources {
+			if source.rank >= from && source.rank <= to {
+				sources[name] = layer{name: source.name, rank: from - 1}
+			}
+		}
+	}
+}
+
+func indexOfTag(tags []language.Tag, tag language.Tag) int {
+	for idx := range tags {
+		if tags[idx] == tag {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// placeholdersOf returns placeholders of the key, nil for unknown builders and keys.
+func placeholdersOf(builder *catalog.Builder, key string) []string {
+	reg, ok := lookupRegistry(builder)
+	if !ok {
+		return nil
+	}
+
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	if meta, ok := reg.meta[key]; ok {
+		return meta.placeholders
+	}
+
+	return nil
+}
+
+// pluralsOf returns plural selections of the key in the language or its closest parent, e.g. ru for ru-KZ.
+func pluralsOf(builder *catalog.Builder, lang language.Tag, key string) []pluralMeta {
+	reg, ok := lookupRegistry(builder)
+	if !ok {
+		return nil
+	}
+
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	meta, ok := reg.meta[key]
+	if !ok {
+		return nil
+	}
+
+	for {
+		if plurals, ok := meta.plurals[lang]; ok {
+			return plurals
+		}
+
+		if lang == language.Und {
+			return nil
+		}
+
+		lang = lang.Parent()
+	}
+}
+
+// set applies the message unless it was supplied by a layer of higher precedence.
+func (r *registry) set(cat *catalog.Builder, lang language.Tag, trans *Translation, source layer) error {
+	if r.shadowed(lang, trans.Key, source) {
+		return nil
+	}
+
+	if err := validatePlaceholders(trans.Placeholders); err != nil {
+		return errors.WithMessagef(err, "validate placeholders for %s", trans.Key)
+	}
+
+	msg, err := trans.message()
+	if err != nil {
+		return errors.WithMessagef(err, "build message for %s", trans.Key)
+	}
+
+	vars, err := r.messageVars(lang, trans)
+	if err != nil {
+		return errors.WithMessagef(err, "resolve variables for %s", trans.Key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The message could be set by a layer of higher precedence while the lock was released.
+	if existing, ok := r.records[lang][trans.Key]; ok && existing.layer.above(source) {
+		return nil
+	}
+
+	if trans.named() {
+		_, replaced := r.records[lang][trans.Key]
+		if err := r.setPlaceholders(trans.Key, trans.Placeholders, replaced); err != nil {
+			return errors.WithMessagef(err, "register placeholders for %s", trans.Key)
+		}
+	}
+
+	if plurals := trans.pluralArgs(); len(plurals) > 0 {
+		meta := r.metaFor(trans.Key)
+		if meta.plurals == nil {
+			meta.plurals = map[language.Tag][]pluralMeta{}
+		}
+
+		meta.plurals[lang] = plurals
+	} else if meta, ok := r.meta[trans.Key]; ok {
+		delete(meta.plurals, lang)
+	}
+
+	if err := cat.Set(lang, trans.Key, append(vars, msg...)...); err != nil {
+		return errors.Wrapf(err, "set message for %s", trans.Key)
+	}
+
+	records, ok := r.records[lang]
+	if !ok {
+		records = map[string]record{}
+		r.records[lang] = records
+	}
+
+	records[trans.Key] = record{trans: trans, layer: source}
+
+	return nil
+}
+
+// shadowed reports whether the message is already supplied by a layer of higher precedence than source.
+func (r *registry) shadowed(lang language.Tag, key string, source layer) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	existing, ok := r.records[lang][key]
+
+	return ok && existing.layer.above(source)
+}
+
+func (r *registry) recompile(cat *catalog.Builder, lang language.Tag, vars map[string]struct{}) error {
+	r.mu.RLock()
+	r.dependentVars(lang, vars)
+
+	dependent := make([]record, 0)
+
+	for _, rec := range r.records[lang] {
+		for _, name := range rec.trans.varRefs() {
+			if _, ok := vars[name]; ok {
+				dependent = append(dependent, rec)
+
+				break
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, rec := range dependent {
+		if err := r.set(cat, lang, rec.trans, rec.layer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *registry) metaFor(key string) *keyMeta {
+	meta, ok := r.meta[key]
+	if !ok {
+		meta = &keyMeta{}
+		r.meta[key] = meta
+	}
+
+	return meta
+}
+
+// setPlaceholders registers placeholders of the key. Placeholders of a message replacing the previous one of the
+// language, e.g. reloaded with renamed or reordered placeholders, replace registered ones, other messages must
+// declare the same placeholders.
+func (r *registry) setPlaceholders(key string, names []string, replaced bool) error {
+	meta := r.metaFor(key)
+
+	if !replaced && meta.placeholders != nil && !equalStrings(meta.placeholders, names) {
+		return errors.Wrapf(ErrInvalidPlaceholder, "placeholders %v do not match previously loaded %v", names, meta.placeholders)
+	}
+
+	meta.placeholders = names
+
+	return nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *registry) record(lang language.Tag, key string) (*Translation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, ok := r.records[lang][key]
+
+	return rec.trans, ok
+}
+
+func (r *registry) setOverlay(locales *overlay) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locales = locales
+}
+
+func (r *registry) overlay() *overlay {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.locales
+}
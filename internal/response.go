@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrResponseTooLarge      = errors.New("response body is too large")
+	ErrUnexpectedContentType = errors.New("unexpected content type")
+)
+
+const (
+	defaultMaxBodySize = 10 << 20
+	// drainLimit limits how much of unread body is discarded to reuse the connection.
+	drainLimit = 64 << 10
+	// errorBodyLimit limits how much of the body is kept in ResponseError.
+	errorBodyLimit = 512
+)
+
+// defaultContentTypes are accepted by default, text/plain is what most servers send for files without extension.
+var defaultContentTypes = []string{"application/json", "text/json", "text/plain"}
+
+// ResponseError describes unexpected response status.
+type ResponseError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	msg := "got status " + strconv.Itoa(e.StatusCode) + " from " + e.URL
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+
+	return msg
+}
+
+func (e *ResponseError) Unwrap() error {
+	return ErrInvalidResponseCode
+}
+
+// WithMaxBodySize limits size of the (decompressed) response body.
+func (e *ExternalLoader) WithMaxBodySize(size int64) *ExternalLoader {
+	e.maxBodySize = size
+
+	return e
+}
+
+// WithContentTypes sets accepted media types of responses, empty Content-Type is always accepted.
+func (e *ExternalLoader) WithContentTypes(types ...string) *ExternalLoader {
+	e.contentTypes = types
+
+	return e
+}
+
+func (e *ExternalLoader) checkContentType(header http.Header) error {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return errors.Wrapf(ErrUnexpectedContentType, "parse %q: %s", contentType, err.Error())
+	}
+
+	if strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+
+	for _, allowed := range e.contentTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(ErrUnexpectedContentType, "%q", mediaType)
+}
+
+func (e *ExternalLoader) readBody(response *http.Response) ([]byte, error) {
+	body := response.Body
+
+	if strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, errors.Wrap(err, "open gzip body")
+		}
+
+		defer func() {
+			_ = reader.Close()
+		}()
+
+		body = reader
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, e.maxBodySize+1))
+	if err != nil {
+		return nil, &retryableError{err: errors.Wrap(err, "read response")}
+	}
+
+	if int64(len(data)) > e.maxBodySize {
+		return nil, errors.Wrapf(ErrResponseTooLarge, "limit is %d bytes", e.maxBodySize)
+	}
+
+	return data, nil
+}
+
+func responseError(target string, response *http.Response) *ResponseError {
+	data, _ := io.ReadAll(io.LimitReader(response.Body, errorBodyLimit+1))
+
+	body := strings.ToValidUTF8(string(data), "")
+	if len(data) > errorBodyLimit {
+		body = strings.ToValidUTF8(string(data[:errorBodyLimit]), "") + "..."
+	}
+
+	return &ResponseError{URL: target, StatusCode: response.StatusCode, Body: body}
+}
+
+// closeBody drains the rest of the body, so connection may be reused, and closes it.
+func closeBody(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, drainLimit))
+	_ = body.Close()
+}
@@ -0,0 +1,104 @@
+package internal_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestExternalLoader_Response(t *testing.T) {
+	t.Parallel()
+
+	payload := `[{"key": "Published", "translation": "Remote"}]`
+
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(payload))
+	require.NoError(t, writer.Close())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gzip/en":
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(compressed.Bytes())
+		case "/html/en":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(payload))
+		case "/large/en":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(payload))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(strings.Repeat("x", 1024)))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	load := func(t *testing.T, loader *ExternalLoader) (string, error) {
+		builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+		require.NoError(t, err)
+
+		err = loader.Load(context.Background(), builder)
+
+		return message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"), err
+	}
+
+	t.Run("gzip", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/gzip", nil))
+		require.NoError(t, err)
+		assert.Equal(t, "Remote", translated)
+	})
+
+	t.Run("content type", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/html", nil))
+		assert.True(t, errors.Is(err, ErrUnexpectedContentType))
+		assert.Equal(t, "Embedded", translated)
+
+		_, err = load(t, NewExternalLoader(srv.URL+"/html", nil).WithContentTypes("text/html"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := load(t, NewExternalLoader(srv.URL+"/large", nil).WithMaxBodySize(10))
+		assert.True(t, errors.Is(err, ErrResponseTooLarge))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := load(t, NewExternalLoader(srv.URL+"/missing", nil))
+		assert.True(t, errors.Is(err, ErrInvalidResponseCode))
+
+		var respErr *ResponseError
+		require.True(t, errors.As(err, &respErr))
+		assert.Equal(t, http.StatusBadRequest, respErr.StatusCode)
+		assert.Equal(t, srv.URL+"/missing/en", respErr.URL)
+		assert.Equal(t, strings.Repeat("x", 512)+"...", respErr.Body)
+	})
+}
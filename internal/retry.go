@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxRetryAfter caps delays requested by Retry-After headers when MaxBackoff is not set.
+const maxRetryAfter = 30 * time.Second
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	// MaxBackoff limits delays including Retry-After of responses, which is limited to 30 seconds if zero.
+	MaxBackoff        time.Duration
+	Multiplier        float64
+	Jitter            float64
+	RetryableStatuses []int
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) retryableStatus(code int) bool {
+	for _, status := range p.RetryableStatuses {
+		if status == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns delay before the next attempt after the given number of failed attempts.
+func (p RetryPolicy) backoff(failed int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(failed-1))
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return p.limit(time.Duration(delay))
+}
+
+func (p RetryPolicy) limit(delay time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	if delay < 0 {
+		return 0
+	}
+
+	return delay
+}
+
+// limitRetryAfter limits delay requested by the server, by MaxBackoff or maxRetryAfter if it is not set.
+func (p RetryPolicy) limitRetryAfter(delay time.Duration) time.Duration {
+	if p.MaxBackoff <= 0 && delay > maxRetryAfter {
+		return maxRetryAfter
+	}
+
+	return p.limit(delay)
+}
+
+// retryableError marks an attempt failure which may succeed if repeated.
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+func (e *ExternalLoader) withRetry(ctx context.Context, attempt func(ctx context.Context) error) error {
+	var err error
+
+	for failed := 0; failed < e.retry.attempts(); failed++ {
+		if failed > 0 {
+			var retryable *retryableError
+			if !errors.As(err, &retryable) {
+				return err
+			}
+
+			delay := e.retry.backoff(failed)
+			if retryable.after > delay {
+				delay = e.retry.limitRetryAfter(retryable.after)
+			}
+
+			if wErr := sleep(ctx, delay); wErr != nil {
+				return errors.WithMessagef(err, "retry aborted: %s", wErr.Error())
+			}
+		}
+
+		if err = attempt(ctx); err == nil {
+			return nil
+		}
+	}
+
+	if e.retry.attempts() == 1 {
+		return err
+	}
+
+	return errors.WithMessagef(err, "%d attempts failed", e.retry.attempts())
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
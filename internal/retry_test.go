@@ -0,0 +1,110 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/message/catalog"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func flakyServer(failures int32, status int, retryAfter string) (*httptest.Server, *int32) {
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+
+			w.WriteHeader(status)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Published"}]`))
+	}))
+
+	return srv, &requests
+}
+
+func TestExternalLoader_Retry(t *testing.T) {
+	t.Parallel()
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 20 * time.Millisecond
+
+	newBuilder := func(t *testing.T) *catalog.Builder {
+		builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Published"}]`))
+		require.NoError(t, err)
+
+		return builder
+	}
+
+	t.Run("recovers", func(t *testing.T) {
+		t.Parallel()
+
+		srv, requests := flakyServer(2, http.StatusBadGateway, "")
+		defer srv.Close()
+
+		require.NoError(t, NewExternalLoader(srv.URL, nil).WithRetryPolicy(policy).Load(context.Background(), newBuilder(t)))
+		assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+	})
+
+	t.Run("retry after is capped", func(t *testing.T) {
+		t.Parallel()
+
+		srv, requests := flakyServer(1, http.StatusServiceUnavailable, "3600")
+		defer srv.Close()
+
+		started := time.Now()
+
+		require.NoError(t, NewExternalLoader(srv.URL, nil).WithRetryPolicy(policy).Load(context.Background(), newBuilder(t)))
+		assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+		assert.Less(t, time.Since(started), time.Second)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		srv, requests := flakyServer(5, http.StatusBadGateway, "")
+		defer srv.Close()
+
+		err := NewExternalLoader(srv.URL, nil).WithRetryPolicy(policy).Load(context.Background(), newBuilder(t))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidResponseCode))
+		assert.Contains(t, err.Error(), "3 attempts failed")
+		assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		t.Parallel()
+
+		srv, requests := flakyServer(1, http.StatusNotFound, "")
+		defer srv.Close()
+
+		require.Error(t, NewExternalLoader(srv.URL, nil).WithRetryPolicy(policy).Load(context.Background(), newBuilder(t)))
+		assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		t.Parallel()
+
+		srv, requests := flakyServer(1, http.StatusBadGateway, "")
+		defer srv.Close()
+
+		err := NewExternalLoader(srv.URL, nil).Load(context.Background(), newBuilder(t))
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "attempts failed")
+		assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+	})
+}
@@ -0,0 +1,258 @@
+package internal
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid bundle signature")
+	ErrChecksumMismatch = errors.New("bundle checksum mismatch")
+)
+
+const defaultSignatureSuffix = ".sig"
+
+// bundleCheck verifies a fetched bundle before it is applied and returns the proof to cache along with it.
+type bundleCheck func(ctx context.Context, lang language.Tag, langURL string, bundle []byte) (*bundleProof, error)
+
+// bundleProof is what a bundle was verified by, so the cached bundle is verified again before it is applied.
+type bundleProof struct {
+	Version   int64  `json:"version"`
+	Signature []byte `json:"signature"`
+	// Manifest is the checksum manifest signed by the signature, the bundle itself is signed without it.
+	Manifest []byte `json:"manifest,omitempty"`
+}
+
+type bundleVerifier interface {
+	// begin is called once per load and returns the check for every bundle of the load.
+	begin(ctx context.Context, e *ExternalLoader) (bundleCheck, error)
+	// verifyIndex verifies the discovered languages index.
+	verifyIndex(ctx context.Context, e *ExternalLoader, indexURL string, index []byte) error
+	// verifyCached verifies the cached bundle by the proof stored along with it.
+	verifyCached(lang language.Tag, bundle []byte, proof *bundleProof) error
+}
+
+// WithSignatures requires every bundle and the languages index to have a detached ed25519 signature
+// at their URL with the ".sig" suffix, made by any of the keys. See SignBundle for the signature format.
+func (e *ExternalLoader) WithSignatures(keys ...ed25519.PublicKey) *ExternalLoader {
+	e.verifier = newSignatureVerifier(keys)
+
+	return e
+}
+
+// WithChecksumManifest requires every bundle to match sha256 checksum from the manifest at the path
+// relative to the base URL. The manifest is a JSON object of language tags to hex encoded checksums,
+// signed the same way as bundles of WithSignatures with the "manifest" subject, the languages index
+// is signed with the "index" subject.
+func (e *ExternalLoader) WithChecksumManifest(manifestPath string, keys ...ed25519.PublicKey) *ExternalLoader {
+	e.verifier = &manifestVerifier{path: manifestPath, signature: newSignatureVerifier(keys)}
+
+	return e
+}
+
+const (
+	manifestSubject = "manifest"
+	indexSubject    = "index"
+)
+
+// signatureFile is the content of a detached signature.
+type signatureFile struct {
+	Version   int64  `json:"version"`
+	Signature []byte `json:"signature"`
+}
+
+// SignBundle returns the detached signature of the data, e.g. a bundle of the language, for WithSignatures.
+// The signature covers the subject, which is the language tag for bundles, "index" for the languages index
+// and "manifest" for the checksum manifest, and the version, so a bundle can't be served as another
+// language and, once a newer version was accepted, older ones are rejected.
+func SignBundle(key ed25519.PrivateKey, subject string, version int64, data []byte) ([]byte, error) {
+	signed, err := json.Marshal(signatureFile{Version: version, Signature: ed25519.Sign(key, signedPayload(subject, version, data))})
+
+	return signed, errors.Wrap(err, "encode signature")
+}
+
+func signedPayload(subject string, version int64, data []byte) []byte {
+	payload := make([]byte, 0, len(subject)+len(data)+32)
+	payload = append(payload, "goi18n\n"+subject+"\n"+strconv.FormatInt(version, 10)+"\n"...)
+
+	return append(payload, data...)
+}
+
+type signatureVerifier struct {
+	suffix string
+	keys   []ed25519.PublicKey
+
+	mu       sync.Mutex
+	versions map[string]int64
+}
+
+func newSignatureVerifier(keys []ed25519.PublicKey) *signatureVerifier {
+	return &signatureVerifier{suffix: defaultSignatureSuffix, keys: keys, versions: map[string]int64{}}
+}
+
+func (v *signatureVerifier) begin(_ context.Context, e *ExternalLoader) (bundleCheck, error) {
+	return func(ctx context.Context, lang language.Tag, langURL string, bundle []byte) (*bundleProof, error) {
+		signature, err := v.verify(ctx, e, lang.String(), langURL, bundle)
+		if err != nil {
+			return nil, err
+		}
+
+		return &bundleProof{Version: signature.Version, Signature: signature.Signature}, nil
+	}, nil
+}
+
+func (v *signatureVerifier) verifyIndex(ctx context.Context, e *ExternalLoader, indexURL string, index []byte) error {
+	_, err := v.verify(ctx, e, indexSubject, indexURL, index)
+
+	return err
+}
+
+func (v *signatureVerifier) verifyCached(lang language.Tag, bundle []byte, proof *bundleProof) error {
+	if proof == nil {
+		return errors.Wrapf(ErrInvalidSignature, "no cached signature of %s", lang.String())
+	}
+
+	return v.check(lang.String(), lang.String(), signatureFile{Version: proof.Version, Signature: proof.Signature}, bundle)
+}
+
+// verify checks the signature of the data fetched from the target, versions older than the last accepted
+// version of the subject are rejected.
+func (v *signatureVerifier) verify(ctx context.Context, e *ExternalLoader, subject, target string, data []byte) (signatureFile, error) {
+	response, err := e.fetch(ctx, target+v.suffix, true, nil)
+	if err != nil {
+		return signatureFile{}, errors.WithMessage(err, "fetch signature")
+	}
+
+	var signature signatureFile
+	if err := json.Unmarshal(response.body, &signature); err != nil {
+		return signature, errors.Wrapf(ErrInvalidSignature, "malformed signature of %s", target)
+	}
+
+	return signature, v.check(subject, target, signature, data)
+}
+
+// check verifies the signature of the data by any of the keys, the target only describes the data in errors.
+func (v *signatureVerifier) check(subject, target string, signature signatureFile, data []byte) error {
+	if len(signature.Signature) != ed25519.SignatureSize {
+		return errors.Wrapf(ErrInvalidSignature, "malformed signature of %s", target)
+	}
+
+	payload := signedPayload(subject, signature.Version, data)
+
+	for _, key := range v.keys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, payload, signature.Signature) {
+			return v.accept(subject, signature.Version)
+		}
+	}
+
+	return errors.Wrapf(ErrInvalidSignature, "%s", target)
+}
+
+// accept remembers the version of the subject, an older version than the accepted one is a replay.
+func (v *signatureVerifier) accept(subject string, version int64) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if last, ok := v.versions[subject]; ok && version < last {
+		return errors.Wrapf(ErrInvalidSignature, "%s version %d is older than accepted %d", subject, version, last)
+	}
+
+	v.versions[subject] = version
+
+	return nil
+}
+
+type manifestVerifier struct {
+	path      string
+	signature *signatureVerifier
+}
+
+func (v *manifestVerifier) verifyIndex(ctx context.Context, e *ExternalLoader, indexURL string, index []byte) error {
+	return v.signature.verifyIndex(ctx, e, indexURL, index)
+}
+
+func (v *manifestVerifier) begin(ctx context.Context, e *ExternalLoader) (bundleCheck, error) {
+	manifestURL, err := url.JoinPath(e.baseURL, v.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "join url path")
+	}
+
+	var (
+		checksums map[string]string
+		proof     *bundleProof
+	)
+
+	err = e.withRetry(ctx, func(ctx context.Context) error {
+		response, err := e.fetch(ctx, manifestURL, false, nil)
+		if err != nil {
+			return errors.WithMessage(err, "fetch manifest")
+		}
+
+		signature, err := v.signature.verify(ctx, e, manifestSubject, manifestURL, response.body)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(response.body, &checksums); err != nil {
+			return errors.Wrap(err, "decode manifest")
+		}
+
+		proof = &bundleProof{Version: signature.Version, Signature: signature.Signature, Manifest: response.body}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(_ context.Context, lang language.Tag, _ string, bundle []byte) (*bundleProof, error) {
+		if err := matchChecksum(checksums, lang, bundle); err != nil {
+			return nil, err
+		}
+
+		return proof, nil
+	}, nil
+}
+
+// verifyCached verifies the cached bundle by the checksum of the cached manifest, which is verified first.
+func (v *manifestVerifier) verifyCached(lang language.Tag, bundle []byte, proof *bundleProof) error {
+	if proof == nil || proof.Manifest == nil {
+		return errors.Wrapf(ErrChecksumMismatch, "no cached manifest for %s", lang.String())
+	}
+
+	signature := signatureFile{Version: proof.Version, Signature: proof.Signature}
+	if err := v.signature.check(manifestSubject, "cached manifest", signature, proof.Manifest); err != nil {
+		return err
+	}
+
+	var checksums map[string]string
+	if err := json.Unmarshal(proof.Manifest, &checksums); err != nil {
+		return errors.Wrap(err, "decode cached manifest")
+	}
+
+	return matchChecksum(checksums, lang, bundle)
+}
+
+func matchChecksum(checksums map[string]string, lang language.Tag, bundle []byte) error {
+	expected, ok := checksums[lang.String()]
+	if !ok {
+		return errors.Wrapf(ErrChecksumMismatch, "no checksum for %s", lang.String())
+	}
+
+	sum := sha256.Sum256(bundle)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), expected) {
+		return errors.Wrapf(ErrChecksumMismatch, "%s", lang.String())
+	}
+
+	return nil
+}
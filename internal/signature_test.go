@@ -0,0 +1,170 @@
+package internal_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestExternalLoader_Signatures(t *testing.T) {
+	t.Parallel()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	bundle := []byte(`[{"key": "Published", "translation": "Remote"}]`)
+	tampered := []byte(`[{"key": "Published", "translation": "Injected"}]`)
+	sum := sha256.Sum256(bundle)
+	manifest := []byte(`{"en": "` + hex.EncodeToString(sum[:]) + `"}`)
+
+	sign := func(subject string, version int64, data []byte) []byte {
+		signature, err := SignBundle(private, subject, version, data)
+		require.NoError(t, err)
+
+		return signature
+	}
+
+	var bundleVersion int64 = 2
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good/en", "/manifest/en", "/language/en", "/replay/en":
+			_, _ = w.Write(bundle)
+		case "/good/en.sig", "/tampered/en.sig":
+			_, _ = w.Write(sign("en", 1, bundle))
+		case "/language/en.sig":
+			_, _ = w.Write(sign("de", 1, bundle))
+		case "/replay/en.sig":
+			_, _ = w.Write(sign("en", atomic.AddInt64(&bundleVersion, -1), bundle))
+		case "/tampered/en", "/tampered-manifest/en":
+			_, _ = w.Write(tampered)
+		case "/manifest/manifest.json", "/tampered-manifest/manifest.json":
+			_, _ = w.Write(manifest)
+		case "/manifest/manifest.json.sig", "/tampered-manifest/manifest.json.sig":
+			_, _ = w.Write(sign("manifest", 1, manifest))
+		case "/good/index", "/manifest/index", "/unsigned-index/index":
+			_, _ = w.Write([]byte(`["en"]`))
+		case "/good/index.sig", "/manifest/index.sig":
+			_, _ = w.Write(sign("index", 1, []byte(`["en"]`)))
+		case "/unsigned-index/en":
+			_, _ = w.Write(bundle)
+		case "/unsigned-index/en.sig":
+			_, _ = w.Write(sign("en", 1, bundle))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	load := func(t *testing.T, loader *ExternalLoader) (string, error) {
+		builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+		require.NoError(t, err)
+
+		err = loader.Load(context.Background(), builder)
+
+		return message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"), err
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/good", nil).WithSignatures(otherPublic, public))
+		require.NoError(t, err)
+		assert.Equal(t, "Remote", translated)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/good", nil).WithSignatures(otherPublic))
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+		assert.Equal(t, "Embedded", translated)
+	})
+
+	t.Run("tampered bundle", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/tampered", nil).WithSignatures(public))
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+		assert.Equal(t, "Embedded", translated)
+	})
+
+	t.Run("other language", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/language", nil).WithSignatures(public))
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+		assert.Equal(t, "Embedded", translated)
+	})
+
+	t.Run("replayed version", func(t *testing.T) {
+		t.Parallel()
+
+		loader := NewExternalLoader(srv.URL+"/replay", nil).WithSignatures(public)
+
+		_, err := load(t, loader)
+		require.NoError(t, err)
+
+		translated, err := load(t, loader)
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+		assert.Equal(t, "Embedded", translated)
+	})
+
+	t.Run("signed index", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/good", nil).WithDiscovery("index").WithSignatures(public))
+		require.NoError(t, err)
+		assert.Equal(t, "Remote", translated)
+
+		_, err = load(t, NewExternalLoader(srv.URL+"/manifest", nil).WithDiscovery("index").WithChecksumManifest("manifest.json", public))
+		require.NoError(t, err)
+	})
+
+	t.Run("unsigned index", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := load(t, NewExternalLoader(srv.URL+"/unsigned-index", nil).WithDiscovery("index").WithSignatures(public))
+		assert.Error(t, err)
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/manifest", nil).WithChecksumManifest("manifest.json", public))
+		require.NoError(t, err)
+		assert.Equal(t, "Remote", translated)
+	})
+
+	t.Run("manifest mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		translated, err := load(t, NewExternalLoader(srv.URL+"/tampered-manifest", nil).WithChecksumManifest("manifest.json", public))
+		assert.True(t, errors.Is(err, ErrChecksumMismatch))
+		assert.Equal(t, "Embedded", translated)
+	})
+
+	t.Run("manifest bad signature", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := load(t, NewExternalLoader(srv.URL+"/manifest", nil).WithChecksumManifest("manifest.json", otherPublic))
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+}
@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// snapshotFormat is increased on every incompatible change of the snapshot structure.
+const snapshotFormat = 2
+
+var ErrStaleSnapshot = errors.New("stale snapshot")
+
+type snapshot struct {
+	Format    int
+	Version   string
+	Languages []snapshotLanguage
+}
+
+type snapshotLanguage struct {
+	Tag      string
+	Vars     []snapshotVar
+	Messages []snapshotMessage
+}
+
+type snapshotVar struct {
+	Name  string
+	Text  string
+	Layer snapshotLayer
+}
+
+type snapshotLayer struct {
+	Name string
+	Rank int
+	Sub  []int
+}
+
+func snapshotLayerOf(source layer) snapshotLayer {
+	return snapshotLayer{Name: source.name, Rank: source.rank, Sub: source.sub}
+}
+
+func (l snapshotLayer) layer() layer {
+	return layer{name: l.Name, rank: l.Rank, sub: l.Sub}
+}
+
+type snapshotMessage struct {
+	Message
+	Layer snapshotLayer
+}
+
+// WriteSnapshot writes all messages and variables of the builder loaded from locale files and loaders.
+// Messages set directly to the builder, e.g. by SetExtendBuilder, are not included.
+func WriteSnapshot(w io.Writer, builder *catalog.Builder, version string) error {
+	reg, ok := lookupRegistry(builder)
+	if !ok {
+		reg = newRegistry()
+	}
+
+	snap := reg.snapshot()
+	snap.Format, snap.Version = snapshotFormat, version
+
+	zw := gzip.NewWriter(w)
+
+	if err := gob.NewEncoder(zw).Encode(&snap); err != nil {
+		return errors.Wrap(err, "encode snapshot")
+	}
+
+	return errors.Wrap(zw.Close(), "compress snapshot")
+}
+
+// ReadSnapshot builds the catalog from the snapshot. ErrStaleSnapshot is returned if the snapshot was written
+// by another version of the library or with another version.
+func ReadSnapshot(r io.Reader, version string) (*catalog.Builder, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "decompress snapshot")
+	}
+
+	var snap snapshot
+	if err := gob.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, errors.Wrap(err, "decode snapshot")
+	}
+
+	if snap.Format != snapshotFormat {
+		return nil, errors.Wrapf(ErrStaleSnapshot, "format %d, expected %d", snap.Format, snapshotFormat)
+	}
+
+	if snap.Version != version {
+		return nil, errors.Wrapf(ErrStaleSnapshot, "version %q, expected %q", snap.Version, version)
+	}
+
+	return restoreSnapshot(snap)
+}
+
+// restoreSnapshot builds the catalog from records of the snapshot.
+func restoreSnapshot(snap snapshot) (*catalog.Builder, error) {
+	cat := catalog.NewBuilder()
+
+	if err := registryOf(cat).restore(cat, snap); err != nil {
+		ReleaseBuilder(cat)
+
+		return nil, err
+	}
+
+	if extendBuilder != nil {
+		if err := extendBuilder(cat); err != nil {
+			ReleaseBuilder(cat)
+
+			return nil, errors.Wrap(err, "extend builder")
+		}
+	}
+
+	return cat, nil
+}
+
+// restore sets messages and variables of the snapshot with their original layers.
+func (r *registry) restore(cat *catalog.Builder, snap snapshot) error {
+	for _, item := range snap.Languages {
+		lang, err := language.Parse(item.Tag)
+		if err != nil {
+			return errors.Wrapf(err, "parse language %s", item.Tag)
+		}
+
+		for _, v := range item.Vars {
+			vars := []Translation{{Key: varPrefix + v.Name, Translation: v.Text}}
+
+			if _, err := r.setVars(lang, vars, v.Layer.layer()); err != nil {
+				return errors.WithMessagef(err, "set variables of %s", item.Tag)
+			}
+		}
+
+		for idx := range item.Messages {
+			msg := &item.Messages[idx]
+
+			if err := r.set(cat, lang, msg.Message.translation(), msg.Layer.layer()); err != nil {
+				return errors.WithMessagef(err, "set message of %s", item.Tag)
+			}
+		}
+	}
+
+	return nil
+}
+
+// snapshot returns messages and variables of the registry except those of tenants.
+func (r *registry) snapshot() snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	languages := make(map[language.Tag]*snapshotLanguage)
+
+	languageOf := func(lang language.Tag) *snapshotLanguage {
+		item, ok := languages[lang]
+		if !ok {
+			item = &snapshotLanguage{Tag: lang.String()}
+			languages[lang] = item
+		}
+
+		return item
+	}
+
+	for lang, vars := range r.vars {
+		if _, ok := isTenantLanguage(lang); ok {
+			continue
+		}
+
+		item := languageOf(lang)
+
+		for name, text := range vars {
+			source := r.varLayers[lang][name]
+			item.Vars = append(item.Vars, snapshotVar{Name: name, Text: text, Layer: snapshotLayerOf(source)})
+		}
+
+		sort.Slice(item.Vars, func(i, j int) bool { return item.Vars[i].Name < item.Vars[j].Name })
+	}
+
+	for lang, records := range r.records {
+		if _, ok := isTenantLanguage(lang); ok {
+			continue
+		}
+
+		item := languageOf(lang)
+
+		for _, rec := range records {
+			item.Messages = append(item.Messages, snapshotMessage{
+				Message: messageOf(rec.trans),
+				Layer:   snapshotLayerOf(rec.layer),
+			})
+		}
+
+		sort.Slice(item.Messages, func(i, j int) bool { return item.Messages[i].Key < item.Messages[j].Key })
+	}
+
+	snap := snapshot{Languages: make([]snapshotLanguage, 0, len(languages))}
+	for _, item := range languages {
+		snap.Languages = append(snap.Languages, *item)
+	}
+
+	sort.Slice(snap.Languages, func(i, j int) bool { return snap.Languages[i].Tag < snap.Languages[j].Tag })
+
+	return snap
+}
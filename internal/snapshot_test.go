@@ -0,0 +1,94 @@
+package internal_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "$unit", "translation": "item"},
+  {"key": "Greeting", "placeholders": ["name"], "translation": "Hello, {name}"},
+  {"key": "Items", "plural": {"=0": "no ${unit}s", "one": "%d ${unit}", "other": "%d ${unit}s"}},
+  {"key": "Weight", "digits": 1, "plural": {"one": "%.1f kilogram", "other": "%.1f kilograms"}},
+  {
+    "key": "files in folders",
+    "translation": "${files} in ${folders}",
+    "plurals": {
+      "files": {"arg": 1, "one": "%[1]d file", "other": "%[1]d files"},
+      "folders": {"arg": 2, "one": "%[2]d folder", "other": "%[2]d folders"}
+    }
+  }
+]`))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, WriteSnapshot(&buf, builder, "v1.2.3"))
+
+	t.Run("restored", func(t *testing.T) {
+		t.Parallel()
+
+		restored, err := ReadSnapshot(bytes.NewReader(buf.Bytes()), "v1.2.3")
+		require.NoError(t, err)
+
+		assert.Equal(t, builder.Languages(), restored.Languages())
+
+		want := message.NewPrinter(language.English, message.Catalog(builder))
+		got := message.NewPrinter(language.English, message.Catalog(restored))
+
+		assert.Equal(t, want.Sprintf("Greeting", NamedArgs(builder, "Greeting", map[string]string{"name": "John"})...),
+			got.Sprintf("Greeting", NamedArgs(restored, "Greeting", map[string]string{"name": "John"})...))
+
+		for _, count := range []int{0, 1, 5} {
+			assert.Equal(t, want.Sprintf("Items", count), got.Sprintf("Items", count))
+		}
+
+		assert.Equal(t, "1.0 kilograms", got.Sprintf("Weight", PrepareArgs(restored, language.English, "Weight", []interface{}{"1.0"})...))
+		assert.Equal(t, "1 file in 2 folders", got.Sprintf("files in folders", 1, 2))
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ReadSnapshot(bytes.NewReader(buf.Bytes()), "v1.2.4")
+		assert.True(t, errors.Is(err, ErrStaleSnapshot))
+	})
+
+	t.Run("stale format", func(t *testing.T) {
+		t.Parallel()
+
+		var old bytes.Buffer
+
+		zw := gzip.NewWriter(&old)
+		require.NoError(t, gob.NewEncoder(zw).Encode(struct {
+			Format  int
+			Version string
+		}{Format: 1, Version: "v1.2.3"}))
+		require.NoError(t, zw.Close())
+
+		_, err := ReadSnapshot(&old, "v1.2.3")
+		assert.True(t, errors.Is(err, ErrStaleSnapshot))
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ReadSnapshot(bytes.NewReader(buf.Bytes()[:buf.Len()/2]), "v1.2.3")
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrStaleSnapshot))
+	})
+}
@@ -0,0 +1,205 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+var ErrInvalidSQLLayout = errors.New("invalid sql layout")
+
+// SQLLayout describes where translations are stored. Empty fields take default names.
+type SQLLayout struct {
+	Table             string
+	KeyColumn         string
+	LanguageColumn    string
+	TranslationColumn string
+	// PluralColumn holds JSON object of plural cases, as in locale files. Null for non-plural messages.
+	PluralColumn string
+
+	// Query replaces the generated query, it must return key, language, translation and plural columns.
+	Query string
+}
+
+func (l SQLLayout) query() (string, error) {
+	if l.Query != "" {
+		return l.Query, nil
+	}
+
+	names := []*string{&l.Table, &l.KeyColumn, &l.LanguageColumn, &l.TranslationColumn, &l.PluralColumn}
+	defaults := []string{"translations", "key", "language", "translation", "plural"}
+
+	for idx, name := range names {
+		if *name == "" {
+			*name = defaults[idx]
+		}
+
+		for _, part := range strings.Split(*name, ".") {
+			if !isIdentifier(part) {
+				return "", errors.Wrapf(ErrInvalidSQLLayout, "bad identifier %q", *name)
+			}
+		}
+	}
+
+	return "SELECT " + l.KeyColumn + ", " + l.LanguageColumn + ", " + l.TranslationColumn + ", " + l.PluralColumn +
+		" FROM " + l.Table + " ORDER BY " + l.LanguageColumn + ", " + l.KeyColumn, nil
+}
+
+// SQLLoader loads translations from a database table.
+type SQLLoader struct {
+	db         *sql.DB
+	layout     SQLLayout
+	validation *ValidationPolicy
+
+	mu sync.Mutex
+}
+
+func NewSQLLoader(db *sql.DB, layout SQLLayout) *SQLLoader {
+	return &SQLLoader{db: db, layout: layout}
+}
+
+// WithValidation makes loader apply only messages accepted by the policy, rejected ones are reported.
+func (l *SQLLoader) WithValidation(policy ValidationPolicy) *SQLLoader {
+	l.validation = &policy
+
+	return l
+}
+
+func (l *SQLLoader) Load(ctx context.Context, cat *catalog.Builder) error {
+	_, err := l.LoadChanged(ctx, cat)
+
+	return err
+}
+
+// LoadChanged loads translations of languages whose rows changed since the previous load into the builder, only
+// languages the context is restricted to in lazy mode.
+func (l *SQLLoader) LoadChanged(ctx context.Context, cat *catalog.Builder) (bool, error) {
+	byLang, order, fingerprints, err := l.query(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var (
+		changed bool
+		errs    LoadErrors
+		reg     = registryOf(cat)
+	)
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return l.validation.isReference(order[i]) && !l.validation.isReference(order[j])
+	})
+
+	for _, lang := range order {
+		if !selectedLanguage(ctx, lang) || reg.fingerprint(l, lang) == fingerprints[lang] {
+			continue
+		}
+
+		changed = true
+
+		err := applyTranslations(ctx, byLang[lang], lang, cat, l.validation)
+
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			errs.Errors = append(errs.Errors, &LoadError{Language: lang, Source: "sql", Err: err})
+
+			continue
+		}
+
+		if err != nil {
+			return true, &LoadError{Language: lang, Source: "sql", Err: err}
+		}
+
+		reg.setFingerprint(l, lang, fingerprints[lang])
+	}
+
+	return changed, errs.errOrNil()
+}
+
+// query returns translations by language along with fingerprints of rows of every language.
+func (l *SQLLoader) query(ctx context.Context) (map[language.Tag][]Translation, []language.Tag, map[language.Tag][sha256.Size]byte, error) {
+	query, err := l.layout.query()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows, err := l.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "query translations")
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var (
+		byLang = map[language.Tag][]Translation{}
+		order  []language.Tag
+		hashes = map[language.Tag]hash.Hash{}
+	)
+
+	for rows.Next() {
+		var (
+			key, langName       string
+			translation, plural sql.NullString
+		)
+
+		if err := rows.Scan(&key, &langName, &translation, &plural); err != nil {
+			return nil, nil, nil, errors.Wrap(err, "scan translation")
+		}
+
+		lang, err := language.Parse(langName)
+		if err != nil {
+			return nil, nil, nil, &LoadError{Language: language.Und, Source: "sql", Err: errors.Wrapf(err, "parse language %s", langName)}
+		}
+
+		if _, ok := hashes[lang]; !ok {
+			hashes[lang] = sha256.New()
+		}
+
+		for _, field := range []string{key, langName, translation.String, plural.String} {
+			hashes[lang].Write([]byte(field))
+			hashes[lang].Write([]byte{0})
+		}
+
+		trans := Translation{Key: key, Translation: translation.String}
+
+		if plural.Valid && plural.String != "" {
+			trans.Plural = &plurals{}
+
+			if err := json.Unmarshal([]byte(plural.String), trans.Plural); err != nil {
+				return nil, nil, nil, &LoadError{Language: lang, Source: "sql", Err: errors.Wrapf(err, "decode plural of %s", key)}
+			}
+		}
+
+		if _, ok := byLang[lang]; !ok {
+			order = append(order, lang)
+		}
+
+		byLang[lang] = append(byLang[lang], trans)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "read translations")
+	}
+
+	fingerprints := make(map[language.Tag][sha256.Size]byte, len(hashes))
+
+	for lang, sum := range hashes {
+		var fingerprint [sha256.Size]byte
+
+		copy(fingerprint[:], sum.Sum(nil))
+		fingerprints[lang] = fingerprint
+	}
+
+	return byLang, order, fingerprints, nil
+}
@@ -0,0 +1,172 @@
+package internal_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+// fakeDB is a database/sql driver returning configured rows for any query.
+type fakeDB struct {
+	mu      sync.Mutex
+	queries []string
+	rows    [][]driver.Value
+	err     error
+}
+
+func (f *fakeDB) set(rows ...[]driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rows = rows
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return f }
+func (f *fakeDB) Open(string) (driver.Conn, error)             { return &fakeConn{db: f}, nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.db.queries = append(c.db.queries, query)
+	if c.db.err != nil {
+		return nil, c.db.err
+	}
+
+	return &fakeRows{rows: c.db.rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"key", "language", "translation", "plural"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
+
+func TestSQLLoader(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeDB{}
+	fake.set(
+		[]driver.Value{"Published", "en", "Published (db)", nil},
+		[]driver.Value{"Published", "ru", "Опубликовано (бд)", nil},
+		[]driver.Value{"Items", "en", nil, `{"one": "%d item", "other": "%d items"}`},
+	)
+
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { _ = db.Close() })
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+	require.NoError(t, err)
+
+	loader := NewSQLLoader(db, SQLLayout{Table: "i18n.messages", KeyColumn: "message_key"})
+
+	changed, err := loader.LoadChanged(context.Background(), builder)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.Equal(t,
+		"SELECT message_key, language, translation, plural FROM i18n.messages ORDER BY language, message_key",
+		fake.queries[0],
+	)
+
+	en := message.NewPrinter(language.English, message.Catalog(builder))
+	assert.Equal(t, "Published (db)", en.Sprintf("Published"))
+	assert.Equal(t, "1 item", en.Sprintf("Items", 1))
+	assert.Equal(t, "3 items", en.Sprintf("Items", 3))
+	assert.Equal(t, "Опубликовано (бд)", message.NewPrinter(language.Russian, message.Catalog(builder)).Sprintf("Published"))
+
+	changed, err = loader.LoadChanged(context.Background(), builder)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	fake.set([]driver.Value{"Published", "en", "Edited", nil})
+
+	changed, err = loader.LoadChanged(context.Background(), builder)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Edited", en.Sprintf("Published"))
+}
+
+func TestSQLLoader_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bad layout", func(t *testing.T) {
+		t.Parallel()
+
+		db := sql.OpenDB(&fakeDB{})
+		t.Cleanup(func() { _ = db.Close() })
+
+		err := NewSQLLoader(db, SQLLayout{Table: "messages; DROP TABLE users"}).Load(context.Background(), nil)
+		assert.True(t, errors.Is(err, ErrInvalidSQLLayout))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		t.Parallel()
+
+		fake := &fakeDB{err: errors.New("connection refused")}
+
+		db := sql.OpenDB(fake)
+		t.Cleanup(func() { _ = db.Close() })
+
+		err := NewSQLLoader(db, SQLLayout{Query: "SELECT * FROM view"}).Load(context.Background(), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection refused")
+		assert.Equal(t, []string{"SELECT * FROM view"}, fake.queries)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		t.Parallel()
+
+		fake := &fakeDB{}
+		fake.set(
+			[]driver.Value{"Published", "en", "<script>alert(1)</script>", nil},
+			[]driver.Value{"Title", "en", "Title (db)", nil},
+		)
+
+		db := sql.OpenDB(fake)
+		t.Cleanup(func() { _ = db.Close() })
+
+		builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+		require.NoError(t, err)
+
+		err = NewSQLLoader(db, SQLLayout{}).WithValidation(ValidationPolicy{ForbidHTML: true}).Load(context.Background(), builder)
+		assert.True(t, IsPartial(err))
+		assert.True(t, errors.Is(err, ErrForbiddenHTML))
+
+		printer := message.NewPrinter(language.English, message.Catalog(builder))
+		assert.Equal(t, "Embedded", printer.Sprintf("Published"))
+		assert.Equal(t, "Title (db)", printer.Sprintf("Title"))
+	})
+}
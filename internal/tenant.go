@@ -0,0 +1,377 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+const (
+	// tenantRank is above ranks of all other layers, so overrides of a tenant take precedence over everything.
+	tenantRank = math.MaxInt32
+	// inheritedRank marks messages the tenant no longer overrides, they follow messages of the base language.
+	inheritedRank = tenantRank - 1
+)
+
+// tenantExtension prefixes the private use extension of languages of tenant messages, e.g. ru-x-tenant-1.
+const tenantExtension = "x-tenant-"
+
+var (
+	tenantIDsMu sync.Mutex
+	tenantIDs   = map[string]int{}
+)
+
+// tenantID returns the number of the tenant, assigning it on first use, so tenant names are not restricted by
+// the syntax of language tags.
+func tenantID(tenant string) int {
+	tenantIDsMu.Lock()
+	defer tenantIDsMu.Unlock()
+
+	id, ok := tenantIDs[tenant]
+	if !ok {
+		id = len(tenantIDs) + 1
+		tenantIDs[tenant] = id
+	}
+
+	return id
+}
+
+// TenantLanguage returns the language of messages of the tenant in lang. Catalogs resolve keys the tenant does
+// not override by the parent language, which is lang, so printers of the language see the tenant overrides
+// over messages of the catalog.
+func TenantLanguage(lang language.Tag, tenant string) language.Tag {
+	return tenantLanguage(lang, tenantID(tenant))
+}
+
+func tenantLanguage(lang language.Tag, id int) language.Tag {
+	ext, err := language.ParseExtension(tenantExtension + strconv.Itoa(id))
+	if err != nil {
+		return lang
+	}
+
+	tag, err := language.Compose(lang, ext)
+	if err != nil {
+		return lang
+	}
+
+	return tag
+}
+
+// isTenantLanguage reports whether the language holds messages of a tenant, the id is returned if so.
+func isTenantLanguage(lang language.Tag) (int, bool) {
+	ext, ok := lang.Extension('x')
+	if !ok || !strings.HasPrefix(ext.String(), tenantExtension) {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(ext.String(), tenantExtension))
+
+	return id, err == nil
+}
+
+// Languages returns languages of the catalog except languages of tenant messages.
+func Languages(cat *catalog.Builder) []language.Tag {
+	languages := cat.Languages()
+	filtered := languages[:0:0]
+
+	for _, lang := range languages {
+		if _, ok := isTenantLanguage(lang); !ok {
+			filtered = append(filtered, lang)
+		}
+	}
+
+	return filtered
+}
+
+type tenantKey struct{}
+
+// tenantLoad collects languages and keys applied by a load of tenant overrides.
+type tenantLoad struct {
+	id int
+
+	mu      sync.Mutex
+	applied map[language.Tag]map[string]struct{}
+}
+
+func withTenant(ctx context.Context, load *tenantLoad) context.Context {
+	return context.WithValue(ctx, tenantKey{}, load)
+}
+
+func tenantFrom(ctx context.Context) (*tenantLoad, bool) {
+	load, ok := ctx.Value(tenantKey{}).(*tenantLoad)
+
+	return load, ok
+}
+
+// apply maps lang to the language of the tenant and remembers translations as applied.
+func (t *tenantLoad) apply(lang language.Tag, translations []Translation) language.Tag {
+	lang = tenantLanguage(lang, t.id)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keys, ok := t.applied[lang]
+	if !ok {
+		keys = map[string]struct{}{}
+		t.applied[lang] = keys
+	}
+
+	for idx := range translations {
+		keys[translations[idx].Key] = struct{}{}
+	}
+
+	return lang
+}
+
+// LoadTenant loads overrides of the tenant into the base catalog under languages of the tenant, see
+// TenantLanguage, and reports whether they changed. Overrides of languages the loader applied, or of all
+// languages with full set, which are not applied again revert to messages of base. Messages set to the catalog
+// directly, e.g. by loaders wrapped with LoaderWithoutContext, are not kept for the tenant and change base.
+func LoadTenant(ctx context.Context, base *catalog.Builder, tenant string, overrides Loader, full bool) (bool, error) {
+	load := &tenantLoad{id: tenantID(tenant), applied: map[language.Tag]map[string]struct{}{}}
+	reg := registryOf(base)
+
+	if full {
+		reg.forgetFingerprints(overrides)
+	}
+
+	changed, err := loadChanged(withTenant(withLayer(ctx, layer{name: tenant, rank: tenantRank}), load), overrides, base)
+	if err != nil {
+		err = errors.WithMessagef(err, "load overrides of tenant %s", tenant)
+
+		if !IsPartial(err) {
+			return changed, err
+		}
+	}
+
+	if _, ok := overrides.(ChangeLoader); !ok {
+		full = true
+	}
+
+	if changed || full {
+		if inheritErr := reg.revertTenant(base, load, full); inheritErr != nil {
+			return changed, errors.WithMessagef(inheritErr, "revert removed overrides of tenant %s", tenant)
+		}
+	}
+
+	return changed, err
+}
+
+// RefreshTenant recompiles messages of the tenant after base changed, so messages the tenant does not
+// override and variables of base the overrides reference follow base.
+func RefreshTenant(base *catalog.Builder, tenant string) error {
+	reg, ok := lookupRegistry(base)
+	if !ok {
+		return nil
+	}
+
+	id := tenantID(tenant)
+
+	for _, lang := range reg.tenantLanguages(id) {
+		for key, rec := range reg.tenantRecords(lang) {
+			var err error
+
+			switch {
+			case rec.layer.rank == inheritedRank:
+				err = reg.inherit(base, lang, key)
+			case len(rec.trans.varRefs()) > 0:
+				err = reg.set(base, lang, rec.trans, rec.layer)
+			}
+
+			if err != nil {
+				return errors.WithMessagef(err, "refresh %s of tenant %s", key, tenant)
+			}
+		}
+
+		if err := reg.inheritVarUsers(base, lang); err != nil {
+			return errors.WithMessagef(err, "refresh messages with variables of tenant %s", tenant)
+		}
+	}
+
+	return nil
+}
+
+// revertTenant makes overrides and variables of the tenant not applied by the load follow base again.
+func (r *registry) revertTenant(cat *catalog.Builder, load *tenantLoad, full bool) error {
+	languages := r.tenantLanguages(load.id)
+
+	for lang := range load.applied {
+		if indexOfTag(languages, lang) < 0 {
+			languages = append(languages, lang)
+		}
+	}
+
+	changedVars := map[language.Tag]map[string]struct{}{}
+
+	for _, lang := range languages {
+		applied, ok := load.applied[lang]
+		if !ok && !full {
+			continue
+		}
+
+		for key, rec := range r.tenantRecords(lang) {
+			if _, ok := applied[key]; ok || rec.layer.rank != tenantRank {
+				continue
+			}
+
+			if err := r.inherit(cat, lang, key); err != nil {
+				return err
+			}
+		}
+
+		if removed := r.removeVars(lang, applied); len(removed) > 0 {
+			changedVars[lang] = removed
+		}
+	}
+
+	for lang, vars := range changedVars {
+		if err := r.recompile(cat, lang, vars); err != nil {
+			return errors.WithMessage(err, "recompile messages with removed variables")
+		}
+	}
+
+	for _, lang := range languages {
+		if err := r.inheritVarUsers(cat, lang); err != nil {
+			return errors.WithMessage(err, "copy messages with variables of the tenant")
+		}
+	}
+
+	return nil
+}
+
+// inheritVarUsers copies messages of base referencing variables the tenant overrides to the tenant language,
+// so they are compiled with variables of the tenant.
+func (r *registry) inheritVarUsers(cat *catalog.Builder, lang language.Tag) error {
+	r.mu.RLock()
+
+	vars := make(map[string]struct{}, len(r.vars[lang]))
+	for name := range r.vars[lang] {
+		vars[name] = struct{}{}
+	}
+
+	var keys []string
+
+	if len(vars) > 0 {
+		r.dependentVars(lang.Parent(), vars)
+
+		for key, rec := range r.records[lang.Parent()] {
+			if _, ok := r.records[lang][key]; ok {
+				continue
+			}
+
+			for _, name := range rec.trans.varRefs() {
+				if _, ok := vars[name]; ok {
+					keys = append(keys, key)
+
+					break
+				}
+			}
+		}
+	}
+
+	r.mu.RUnlock()
+
+	for _, key := range keys {
+		if err := r.inherit(cat, lang, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tenantLanguages returns languages holding messages or variables of the tenant.
+func (r *registry) tenantLanguages(id int) []language.Tag {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var languages []language.Tag
+
+	for lang := range r.records {
+		if tenant, ok := isTenantLanguage(lang); ok && tenant == id {
+			languages = append(languages, lang)
+		}
+	}
+
+	for lang := range r.vars {
+		if tenant, ok := isTenantLanguage(lang); ok && tenant == id && indexOfTag(languages, lang) < 0 {
+			languages = append(languages, lang)
+		}
+	}
+
+	return languages
+}
+
+// tenantRecords returns a copy of records of the language.
+func (r *registry) tenantRecords(lang language.Tag) map[string]record {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make(map[string]record, len(r.records[lang]))
+	for key, rec := range r.records[lang] {
+		records[key] = rec
+	}
+
+	return records
+}
+
+// removeVars drops variables of the tenant language not applied by the load, base ones are used instead.
+func (r *registry) removeVars(lang language.Tag, applied map[string]struct{}) map[string]struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := map[string]struct{}{}
+
+	for name := range r.vars[lang] {
+		if _, ok := applied[varPrefix+name]; ok {
+			continue
+		}
+
+		delete(r.vars[lang], name)
+		delete(r.varLayers[lang], name)
+		removed[name] = struct{}{}
+	}
+
+	return removed
+}
+
+// inherit replaces the message of the tenant language with the message of base. A key base does not have is
+// set to the key itself, the same as printers render unknown keys.
+func (r *registry) inherit(cat *catalog.Builder, lang language.Tag, key string) error {
+	r.mu.Lock()
+
+	rec, ok := r.records[lang][key]
+	if ok {
+		rec.layer = layer{rank: inheritedRank}
+		r.records[lang][key] = rec
+	}
+
+	trans, source, found := r.baseRecord(lang, key)
+	r.mu.Unlock()
+
+	if !found {
+		trans, source = &Translation{Key: key, Translation: key}, layer{}
+	}
+
+	return r.set(cat, lang, trans, layer{name: source.name, rank: inheritedRank})
+}
+
+// baseRecord returns the message of the key in the closest parent of the tenant language, the caller holds
+// the lock.
+func (r *registry) baseRecord(lang language.Tag, key string) (*Translation, layer, bool) {
+	for lang = lang.Parent(); ; lang = lang.Parent() {
+		if rec, ok := r.records[lang][key]; ok {
+			return rec.trans, rec.layer, true
+		}
+
+		if lang == language.Und {
+			return nil, layer{}, false
+		}
+	}
+}
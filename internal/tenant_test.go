@@ -0,0 +1,107 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestLoadTenant(t *testing.T) {
+	t.Parallel()
+
+	base, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "$entity", "translation": "Project"},
+  {"key": "Created", "placeholders": ["name"], "translation": "${entity} {name} created"},
+  {"key": "Deleted", "translation": "${entity} deleted"}
+]`))
+	require.NoError(t, err)
+
+	_, err = LoadTenant(context.Background(), base, "acme", NewFSLoader(localeFS(`[
+  {"key": "$entity", "translation": "Workspace"},
+  {"key": "Deleted", "translation": "Gone"}
+]`)), true)
+	require.NoError(t, err)
+
+	basePrinter := message.NewPrinter(language.English, message.Catalog(base))
+	tenantPrinter := message.NewPrinter(TenantLanguage(language.English, "acme"), message.Catalog(base))
+	args := NamedArgs(base, "Created", map[string]string{"name": "X"})
+
+	assert.Equal(t, "Project X created", basePrinter.Sprintf("Created", args...))
+	assert.Equal(t, "Project deleted", basePrinter.Sprintf("Deleted"))
+	assert.Equal(t, "Workspace X created", tenantPrinter.Sprintf("Created", args...))
+	assert.Equal(t, "Gone", tenantPrinter.Sprintf("Deleted"))
+	assert.Equal(t, []language.Tag{language.English}, Languages(base))
+
+	source, ok := MessageSource(base, TenantLanguage(language.English, "acme"), "Deleted")
+	require.True(t, ok)
+	assert.Equal(t, "acme", source)
+
+	t.Run("placeholders mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := LoadTenant(context.Background(), base, "mismatch", NewFSLoader(localeFS(`[
+  {"key": "Created", "placeholders": ["title"], "translation": "{title} created"}
+]`)), true)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadTenant_Reload(t *testing.T) {
+	t.Parallel()
+
+	baseFiles := fstest.MapFS{"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "$entity", "translation": "Project"},
+  {"key": "Created", "translation": "${entity} created"},
+  {"key": "Deleted", "translation": "Deleted"}
+]`)}}
+
+	base, err := InitBuilderLayers(context.Background(), baseFiles)
+	require.NoError(t, err)
+
+	tenantFiles := fstest.MapFS{"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Created", "translation": "${entity} made"},
+  {"key": "Deleted", "translation": "Gone"},
+  {"key": "Archived", "translation": "Shelved"}
+]`)}}
+
+	_, err = LoadTenant(context.Background(), base, "reload", NewFSLoader(tenantFiles), true)
+	require.NoError(t, err)
+
+	printer := message.NewPrinter(TenantLanguage(language.English, "reload"), message.Catalog(base))
+	assert.Equal(t, "Project made", printer.Sprintf("Created"))
+	assert.Equal(t, "Gone", printer.Sprintf("Deleted"))
+	assert.Equal(t, "Shelved", printer.Sprintf("Archived"))
+
+	// Overrides removed by the tenant revert to base, keys base does not have render as unknown keys.
+	tenantFiles["locales/en/active.json"] = &fstest.MapFile{Data: []byte(`[{"key": "Created", "translation": "${entity} made"}]`)}
+
+	changed, err := LoadTenant(context.Background(), base, "reload", NewFSLoader(tenantFiles), false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Deleted", printer.Sprintf("Deleted"))
+	assert.Equal(t, "Archived", printer.Sprintf("Archived"))
+
+	// Changes of base are followed by messages the tenant does not override and by variables of overrides.
+	baseFiles["locales/en/active.json"] = &fstest.MapFile{Data: []byte(`[
+  {"key": "$entity", "translation": "Workspace"},
+  {"key": "Created", "translation": "${entity} created"},
+  {"key": "Deleted", "translation": "Removed"},
+  {"key": "Archived", "translation": "Archived for good"}
+]`)}
+
+	changed, err = RefreshTranslations(context.Background(), base)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.NoError(t, RefreshTenant(base, "reload"))
+
+	assert.Equal(t, "Workspace made", printer.Sprintf("Created"))
+	assert.Equal(t, "Removed", printer.Sprintf("Deleted"))
+	assert.Equal(t, "Archived for good", printer.Sprintf("Archived"))
+}
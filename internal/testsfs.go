@@ -26,6 +26,12 @@ var TestFS = fstest.MapFS{
       "=0": "нет пауков",
       "=2": "всего пара пауков"
     }
+  },
+  {
+    "key": "transition",
+    "description": "Для тестов, не трогать",
+    "placeholders": ["from", "to"],
+    "translation": "Переход в «{to}» из «{from}» запрещён"
   }
 ]`),
 		Mode:    0555,
@@ -48,6 +54,12 @@ var TestFS = fstest.MapFS{
       "=0": "no spiders",
       "=2": "just pair of spiders"
     }
+  },
+  {
+    "key": "transition",
+    "description": "Для тестов, не трогать",
+    "placeholders": ["from", "to"],
+    "translation": "Transition from '{from}' to '{to}' not allowed"
   }
 ]`),
 		Mode:    0555,
@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrVerbMismatch    = errors.New("verbs do not match reference language")
+	ErrMessageTooLong  = errors.New("message is too long")
+	ErrForbiddenHTML   = errors.New("forbidden html")
+	ErrTooManyMessages = errors.New("too many messages")
+)
+
+var validationPolicy *ValidationPolicy
+
+// SetValidationPolicy sets the policy applied to locale files, nil disables validation. Rejected messages
+// do not stop loading, they are returned as LoadErrors with ValidationError, so Init returns an error for
+// which IsPartial is true along with the initialized catalog.
+func SetValidationPolicy(policy *ValidationPolicy) {
+	validationPolicy = policy
+}
+
+var htmlTag = regexp.MustCompile(`<\s*(/?)\s*([a-zA-Z][a-zA-Z0-9-]*)([^>]*)>|<!--`)
+
+// ValidationPolicy describes which messages are accepted into the catalog. Zero values disable checks.
+type ValidationPolicy struct {
+	// ReferenceLanguage messages must have the same verbs as message of this language. Files and bundles of
+	// the reference language are applied before other languages of the same load.
+	ReferenceLanguage language.Tag
+	// MaxLength limits every text of the message, in runes.
+	MaxLength int
+	// ForbidHTML rejects messages with HTML tags, except AllowedTags without attributes.
+	ForbidHTML  bool
+	AllowedTags []string
+	// MaxMessages limits size of a bundle, larger bundles are rejected as a whole.
+	MaxMessages int
+}
+
+// RejectedMessage is a message which was not applied, previous value is kept.
+type RejectedMessage struct {
+	Key    string
+	Reason error
+}
+
+// ValidationError lists rejected messages of a bundle, the rest of the bundle was applied.
+type ValidationError struct {
+	Language language.Tag
+	Rejected []RejectedMessage
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Rejected))
+	for _, rejected := range e.Rejected {
+		messages = append(messages, rejected.Key+": "+rejected.Reason.Error())
+	}
+
+	return "rejected messages for " + e.Language.String() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	for _, rejected := range e.Rejected {
+		if errors.Is(rejected.Reason, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isReference reports whether other languages are checked against lang.
+func (p *ValidationPolicy) isReference(lang language.Tag) bool {
+	return p != nil && p.ReferenceLanguage != language.Und && p.ReferenceLanguage == lang
+}
+
+// validate splits translations into accepted and rejected ones.
+func (p *ValidationPolicy) validate(reg *registry, lang language.Tag, translations []Translation) ([]Translation, []RejectedMessage, error) {
+	if p.MaxMessages > 0 && len(translations) > p.MaxMessages {
+		return nil, nil, errors.Wrapf(ErrTooManyMessages, "%d messages, at most %d allowed", len(translations), p.MaxMessages)
+	}
+
+	var (
+		accepted = make([]Translation, 0, len(translations))
+		rejected []RejectedMessage
+	)
+
+	for idx := range translations {
+		if err := p.check(reg, lang, &translations[idx]); err != nil {
+			rejected = append(rejected, RejectedMessage{Key: translations[idx].Key, Reason: err})
+
+			continue
+		}
+
+		accepted = append(accepted, translations[idx])
+	}
+
+	return accepted, rejected, nil
+}
+
+func (p *ValidationPolicy) check(reg *registry, lang language.Tag, trans *Translation) error {
+	for _, text := range trans.texts() {
+		if p.MaxLength > 0 && utf8.RuneCountInString(text) > p.MaxLength {
+			return errors.Wrapf(ErrMessageTooLong, "%d runes, at most %d allowed", utf8.RuneCountInString(text), p.MaxLength)
+		}
+
+		if p.ForbidHTML {
+			if err := p.checkHTML(text); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.ReferenceLanguage == language.Und || p.ReferenceLanguage == lang || trans.isVar() {
+		return nil
+	}
+
+	reference, ok := reg.record(p.ReferenceLanguage, trans.Key)
+	if !ok {
+		return nil
+	}
+
+	if err := checkPlaceholders(reference, trans); err != nil {
+		return errors.WithMessagef(err, "reference %s", p.ReferenceLanguage)
+	}
+
+	want, got := reference.verbs(), trans.verbs()
+	if !equalStrings(want, got) {
+		return errors.Wrapf(ErrVerbMismatch, "%v, reference %s has %v", got, p.ReferenceLanguage, want)
+	}
+
+	return nil
+}
+
+// checkPlaceholders rejects messages declaring placeholders other than the reference message ones, and messages
+// omitting placeholders while their texts use placeholders of the reference message, which would be printed as is.
+func checkPlaceholders(reference, trans *Translation) error {
+	if !reference.named() {
+		return nil
+	}
+
+	if trans.named() {
+		if !equalStrings(reference.Placeholders, trans.Placeholders) {
+			return errors.Wrapf(ErrInvalidPlaceholder, "placeholders %v do not match %v", trans.Placeholders, reference.Placeholders)
+		}
+
+		return nil
+	}
+
+	for _, text := range trans.texts() {
+		for _, name := range reference.Placeholders {
+			if strings.Contains(text, "{"+name+"}") || strings.Contains(text, "{"+name+":") {
+				return errors.Wrapf(ErrInvalidPlaceholder, "placeholder %q is used, but placeholders are omitted", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *ValidationPolicy) checkHTML(text string) error {
+	for _, match := range htmlTag.FindAllStringSubmatch(text, -1) {
+		name, rest := strings.ToLower(match[2]), strings.TrimSpace(match[3])
+
+		if name == "" || rest != "" && rest != "/" || indexOf(p.AllowedTags, name) < 0 {
+			return errors.Wrapf(ErrForbiddenHTML, "tag %q", match[0])
+		}
+	}
+
+	return nil
+}
+
+// texts returns all texts of the message.
+func (t *Translation) texts() []string {
+	texts := []string{t.Translation}
+
+	if t.Plural != nil {
+		texts = append(texts, t.Plural.texts()...)
+	}
+
+	for _, name := range t.nestedNames() {
+		texts = append(texts, t.Plurals[name].texts()...)
+	}
+
+	return texts
+}
+
+// verbs returns verbs of the message arguments, plural message and nested plurals are described by their
+// other cases. Different verbs of the same argument are joined with "|".
+func (t *Translation) verbs() []string {
+	text := t.Translation
+	if t.Plural != nil {
+		text = t.Plural.Other
+	}
+
+	texts := []string{text}
+	for _, name := range t.nestedNames() {
+		texts = append(texts, t.Plurals[name].Other)
+	}
+
+	var verbs [][]string
+
+	for _, text := range texts {
+		compiled, err := t.text(text)
+		if err != nil {
+			return nil
+		}
+
+		for idx, verb := range argVerbs(compiled) {
+			for len(verbs) <= idx {
+				verbs = append(verbs, nil)
+			}
+
+			if verb != "" && indexOf(verbs[idx], verb) < 0 {
+				verbs[idx] = append(verbs[idx], verb)
+			}
+		}
+	}
+
+	joined := make([]string, len(verbs))
+	for idx := range verbs {
+		sort.Strings(verbs[idx])
+		joined[idx] = strings.Join(verbs[idx], "|")
+	}
+
+	return joined
+}
@@ -0,0 +1,194 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestExternalLoader_Validation(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/en":
+			_, _ = w.Write([]byte(`[{"key": "Greeting", "translation": "Hello, <b>%s</b>"}]`))
+		case "/ru":
+			_, _ = w.Write([]byte(`[
+  {"key": "Greeting", "translation": "Привет, %s %v"},
+  {"key": "Items", "plural": {"one": "%d предмет", "other": "<script>x</script>%d шт."}},
+  {"key": "Long", "translation": "Очень-очень длинное сообщение, длиннее тридцати символов"},
+  {"key": "Bold", "translation": "<b class=\"x\">Жирный</b>"}
+]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	builder, err := InitBuilder(context.Background(), fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Greeting", "translation": "Hello, %s"},
+  {"key": "Items", "plural": {"one": "%d item", "other": "%d items"}}
+]`)},
+		"locales/ru/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Greeting", "translation": "Привет, %s"},
+  {"key": "Items", "plural": {"one": "%d предмет", "other": "%d предметов"}},
+  {"key": "Long", "translation": "Коротко"},
+  {"key": "Bold", "translation": "<b>Жирный</b>"}
+]`)},
+	})
+	require.NoError(t, err)
+
+	loader := NewExternalLoader(srv.URL, nil).WithValidation(ValidationPolicy{
+		ReferenceLanguage: language.English,
+		MaxLength:         30,
+		ForbidHTML:        true,
+		AllowedTags:       []string{"b"},
+	})
+
+	err = loader.Load(context.Background(), builder)
+	require.Error(t, err)
+	assert.True(t, IsPartial(err))
+	assert.True(t, errors.Is(err, ErrVerbMismatch))
+	assert.True(t, errors.Is(err, ErrForbiddenHTML))
+	assert.True(t, errors.Is(err, ErrMessageTooLong))
+
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid))
+	assert.Equal(t, language.Russian, invalid.Language)
+
+	keys := make([]string, 0, len(invalid.Rejected))
+	for _, rejected := range invalid.Rejected {
+		keys = append(keys, rejected.Key)
+	}
+
+	assert.Equal(t, []string{"Greeting", "Items", "Long", "Bold"}, keys)
+
+	en := message.NewPrinter(language.English, message.Catalog(builder))
+	ru := message.NewPrinter(language.Russian, message.Catalog(builder))
+
+	assert.Equal(t, "Hello, <b>John</b>", en.Sprintf("Greeting", "John"))
+	assert.Equal(t, "Привет, John", ru.Sprintf("Greeting", "John"))
+	assert.Equal(t, "5 предметов", ru.Sprintf("Items", 5))
+	assert.Equal(t, "Коротко", ru.Sprintf("Long"))
+
+	for _, stat := range loader.LastStats() {
+		if stat.Language == language.Russian {
+			assert.Len(t, stat.Rejected, 4)
+			assert.NoError(t, stat.Err)
+		}
+	}
+}
+
+func TestExternalLoader_ValidationMaxMessages(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"key": "Published", "translation": "Remote"}, {"key": "Other", "translation": "Other"}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[{"key": "Published", "translation": "Embedded"}]`))
+	require.NoError(t, err)
+
+	err = NewExternalLoader(srv.URL, nil).WithValidation(ValidationPolicy{MaxMessages: 1}).Load(context.Background(), builder)
+	assert.True(t, errors.Is(err, ErrTooManyMessages))
+	assert.False(t, IsPartial(err))
+	assert.Equal(t, "Embedded", message.NewPrinter(language.English, message.Catalog(builder)).Sprintf("Published"))
+}
+
+func TestValidationPolicy_Local(t *testing.T) {
+	SetValidationPolicy(&ValidationPolicy{ForbidHTML: true})
+	defer SetValidationPolicy(nil)
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "Published", "translation": "Published"},
+  {"key": "Link", "translation": "<a href=\"http://example.com\">link</a>"}
+]`))
+	require.NotNil(t, builder)
+	assert.True(t, IsPartial(err))
+	assert.True(t, errors.Is(err, ErrForbiddenHTML))
+
+	printer := message.NewPrinter(language.English, message.Catalog(builder))
+	assert.Equal(t, "Published", printer.Sprintf("Published"))
+	assert.Equal(t, "Link", printer.Sprintf("Link"))
+}
+
+func TestValidationPolicy_ReferenceFirst(t *testing.T) {
+	SetValidationPolicy(&ValidationPolicy{ReferenceLanguage: language.English})
+	defer SetValidationPolicy(nil)
+
+	builder, err := InitBuilder(context.Background(), fstest.MapFS{
+		"locales/de/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Greeting", "translation": "Hallo, %d"},
+  {
+    "key": "files",
+    "translation": "${files}",
+    "plurals": {"files": {"arg": 1, "one": "%[1]s Datei", "other": "%[1]s Dateien"}}
+  },
+  {"key": "Published", "translation": "Veröffentlicht"}
+]`)},
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Greeting", "translation": "Hello, %s"},
+  {
+    "key": "files",
+    "translation": "${files}",
+    "plurals": {"files": {"arg": 1, "one": "%[1]d file", "other": "%[1]d files"}}
+  },
+  {"key": "Published", "translation": "Published"}
+]`)},
+	})
+	require.NotNil(t, builder)
+	assert.True(t, IsPartial(err))
+	assert.True(t, errors.Is(err, ErrVerbMismatch))
+
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid))
+	assert.Equal(t, language.German, invalid.Language)
+	require.Len(t, invalid.Rejected, 2)
+	assert.Equal(t, "Greeting", invalid.Rejected[0].Key)
+	assert.Equal(t, "files", invalid.Rejected[1].Key)
+
+	assert.Equal(t, "Veröffentlicht", message.NewPrinter(language.German, message.Catalog(builder)).Sprintf("Published"))
+}
+
+func TestValidationPolicy_Placeholders(t *testing.T) {
+	SetValidationPolicy(&ValidationPolicy{ReferenceLanguage: language.English})
+	defer SetValidationPolicy(nil)
+
+	builder, err := InitBuilder(context.Background(), fstest.MapFS{
+		"locales/de/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Omitted", "translation": "Von {from} nach {to}"},
+  {"key": "Renamed", "placeholders": ["von", "nach"], "translation": "Von {von} nach {nach}"},
+  {"key": "Positional", "translation": "Von %[1]v nach %[2]v"}
+]`)},
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "Omitted", "placeholders": ["from", "to"], "translation": "From {from} to {to}"},
+  {"key": "Renamed", "placeholders": ["from", "to"], "translation": "From {from} to {to}"},
+  {"key": "Positional", "placeholders": ["from", "to"], "translation": "From {from} to {to}"}
+]`)},
+	})
+	require.NotNil(t, builder)
+	assert.True(t, IsPartial(err))
+	assert.True(t, errors.Is(err, ErrInvalidPlaceholder))
+
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid))
+	require.Len(t, invalid.Rejected, 2)
+	assert.Equal(t, "Omitted", invalid.Rejected[0].Key)
+	assert.Equal(t, "Renamed", invalid.Rejected[1].Key)
+
+	args := NamedArgs(builder, "Positional", map[string]string{"from": "A", "to": "B"})
+	assert.Equal(t, "Von A nach B", message.NewPrinter(language.German, message.Catalog(builder)).Sprintf("Positional", args...))
+}
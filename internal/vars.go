@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+const varPrefix = "$"
+
+var ErrUndefinedVariable = errors.New("undefined variable")
+
+func (t *Translation) isVar() bool {
+	return strings.HasPrefix(t.Key, varPrefix)
+}
+
+// varRefs returns variables referenced by the message, except nested plurals.
+func (t *Translation) varRefs() []string {
+	texts := []string{t.Translation}
+
+	if t.Plural != nil {
+		texts = append(texts, t.Plural.texts()...)
+	}
+
+	for _, nested := range t.Plurals {
+		texts = append(texts, nested.texts()...)
+	}
+
+	var refs []string
+
+	for _, text := range texts {
+		for _, ref := range varRefs(text) {
+			if _, ok := t.Plurals[ref]; !ok && indexOf(refs, ref) < 0 {
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	return refs
+}
+
+func varRefs(text string) []string {
+	var refs []string
+
+	for {
+		start := strings.Index(text, "${")
+		if start < 0 {
+			return refs
+		}
+
+		text = text[start+2:]
+
+		end := strings.IndexAny(text, "}(")
+		if end < 0 {
+			return refs
+		}
+
+		if name := text[:end]; indexOf(refs, name) < 0 {
+			refs = append(refs, name)
+		}
+
+		text = text[end:]
+	}
+}
+
+func (r *registry) setVars(lang language.Tag, translations []Translation, source layer) (map[string]struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed := map[string]struct{}{}
+
+	for idx := range translations {
+		trans := &translations[idx]
+		if !trans.isVar() {
+			continue
+		}
+
+		name := strings.TrimPrefix(trans.Key, varPrefix)
+		if !isIdentifier(name) {
+			return nil, errors.Errorf("bad variable name %q", trans.Key)
+		}
+
+		if trans.Plural != nil {
+			return nil, errors.Errorf("variable %s can not be plural", trans.Key)
+		}
+
+		vars, ok := r.vars[lang]
+		if !ok {
+			vars = map[string]string{}
+			r.vars[lang] = vars
+			r.varLayers[lang] = map[string]layer{}
+		}
+
+		if existing, ok := r.varLayers[lang][name]; ok && existing.above(source) {
+			continue
+		}
+
+		r.varLayers[lang][name] = source
+
+		if old, ok := vars[name]; ok && old == trans.Translation {
+			continue
+		}
+
+		vars[name] = trans.Translation
+		changed[name] = struct{}{}
+	}
+
+	return changed, nil
+}
+
+func (r *registry) messageVars(lang language.Tag, trans *Translation) ([]catalog.Message, error) {
+	refs := trans.varRefs()
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	vars := make([]catalog.Message, 0, len(refs)+1)
+
+	for _, name := range refs {
+		text, err := r.expandVar(lang, name, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		vars = append(vars, catalog.Var(name, catalog.String(text)))
+	}
+
+	return vars, nil
+}
+
+func (r *registry) expandVar(lang language.Tag, name string, visiting []string) (string, error) {
+	if indexOf(visiting, name) >= 0 {
+		return "", errors.Errorf("variable cycle %s -> %s", strings.Join(visiting, " -> "), name)
+	}
+
+	text, ok := r.vars[lang][name]
+	if _, tenant := isTenantLanguage(lang); !ok && tenant {
+		text, ok = r.vars[lang.Parent()][name]
+	}
+
+	if !ok {
+		return "", errors.Wrapf(ErrUndefinedVariable, "%s%s", varPrefix, name)
+	}
+
+	visiting = append(visiting, name)
+
+	for _, ref := range varRefs(text) {
+		expanded, err := r.expandVar(lang, ref, visiting)
+		if err != nil {
+			return "", err
+		}
+
+		text = strings.ReplaceAll(text, "${"+ref+"}", expanded)
+	}
+
+	return text, nil
+}
+
+func (r *registry) dependentVars(lang language.Tag, vars map[string]struct{}) {
+	for grown := true; grown; {
+		grown = false
+
+		for name, text := range r.vars[lang] {
+			if _, ok := vars[name]; ok {
+				continue
+			}
+
+			for _, ref := range varRefs(text) {
+				if _, ok := vars[ref]; ok {
+					vars[name] = struct{}{}
+					grown = true
+
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	. "github.com/derfenix/goi18n/internal"
+)
+
+func TestVariables(t *testing.T) {
+	t.Parallel()
+
+	builder, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "$brand", "translation": "Acme"},
+  {"key": "$product", "translation": "${brand} Cloud"},
+  {"key": "welcome", "translation": "Welcome to ${product}, %s!"},
+  {"key": "greet", "placeholders": ["name"], "translation": "${product} greets {name}"},
+  {"key": "seats", "plural": {"one": "one ${product} seat", "other": "%d ${product} seats"}}
+]`))
+	require.NoError(t, err)
+
+	printer := message.NewPrinter(language.English, message.Catalog(builder))
+
+	assert.Equal(t, "Welcome to Acme Cloud, Bob!", printer.Sprintf("welcome", "Bob"))
+	assert.Equal(t, "Acme Cloud greets Bob", printer.Sprintf("greet", "Bob"))
+	assert.Equal(t, "one Acme Cloud seat", printer.Sprintf("seats", 1))
+	assert.Equal(t, "5 Acme Cloud seats", printer.Sprintf("seats", 5))
+
+	t.Run("redefined", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"key": "$brand", "translation": "Umbrella"}]`))
+		}))
+		defer srv.Close()
+
+		require.NoError(t, NewExternalLoader(srv.URL, nil).Load(context.Background(), builder))
+
+		assert.Equal(t, "Welcome to Umbrella Cloud, Bob!", printer.Sprintf("welcome", "Bob"))
+		assert.Equal(t, "5 Umbrella Cloud seats", printer.Sprintf("seats", 5))
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := InitBuilder(context.Background(), localeFS(`[{"key": "k", "translation": "${nope}"}]`))
+		assert.True(t, errors.Is(err, ErrUndefinedVariable))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := InitBuilder(context.Background(), localeFS(`[
+  {"key": "$a", "translation": "${b}"},
+  {"key": "$b", "translation": "${a}"},
+  {"key": "k", "translation": "${a}"}
+]`))
+		assert.Error(t, err)
+	})
+}
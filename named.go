@@ -0,0 +1,19 @@
+package i18n
+
+import (
+	"context"
+
+	"github.com/derfenix/goi18n/internal"
+)
+
+func NamedArgs(key string, params interface{}) []interface{} {
+	if builder == nil {
+		return nil
+	}
+
+	return internal.NamedArgs(builder, key, params)
+}
+
+func SprintfNamed(ctx context.Context, key string, params interface{}) string {
+	return Sprintf(ctx, key, NamedArgs(key, params)...)
+}
@@ -0,0 +1,62 @@
+package i18n_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	. "github.com/derfenix/goi18n"
+	"github.com/derfenix/goi18n/internal"
+)
+
+func TestSprintfNamed(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Init(internal.TestFS))
+
+	ruCtx := ContextWithLang(context.Background(), language.Russian)
+	enCtx := ContextWithLang(context.Background(), language.English)
+
+	t.Run("map", func(t *testing.T) {
+		t.Parallel()
+
+		params := map[string]interface{}{"from": "draft", "to": "published"}
+
+		assert.Equal(t, "Переход в «published» из «draft» запрещён", SprintfNamed(ruCtx, "transition", params))
+		assert.Equal(t, "Transition from 'draft' to 'published' not allowed", SprintfNamed(enCtx, "transition", params))
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		t.Parallel()
+
+		params := struct {
+			From   string
+			Target string `i18n:"to"`
+		}{From: "draft", Target: "published"}
+
+		assert.Equal(t, "Transition from 'draft' to 'published' not allowed", SprintfNamed(enCtx, "transition", &params))
+	})
+
+	t.Run("missing param", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "Transition from 'draft' to '{to}' not allowed", SprintfNamed(enCtx, "transition", map[string]string{"from": "draft"}))
+	})
+
+	t.Run("positional still works", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "Transition from 'a' to 'b' not allowed", GetPrinter(language.English).Sprintf("transition", "a", "b"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		err := NewError("transition").WithNamedParams(map[string]interface{}{"to": "published", "from": "draft"})
+
+		assert.Equal(t, "Transition from 'draft' to 'published' not allowed", err.Translate(enCtx))
+	})
+}
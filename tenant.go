@@ -0,0 +1,134 @@
+package i18n
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/derfenix/goi18n/internal"
+)
+
+var (
+	tenantsMu sync.RWMutex
+	tenants   = map[string]internal.Loader{}
+
+	// tenantsLoadMu serializes loads of tenant overrides.
+	tenantsLoadMu sync.Mutex
+)
+
+// SetTenantOverrides loads translations of the loader over the base catalog for the tenant, e.g. NewFSLoader
+// with a tenant's own wording. Overrides are kept in the base catalog and keys the tenant does not override
+// are resolved by base messages, so the catalog is not copied per tenant. Must be called after Init, tenant
+// overrides are not supported with lazy loading. RefreshTranslations reloads overrides when the loader reports
+// changes, overrides removed by the tenant revert to base messages. The loader must apply messages by loaders
+// of this package, messages set to the catalog directly, e.g. by LoaderWithoutContext, change base.
+func SetTenantOverrides(ctx context.Context, tenant string, loader internal.Loader) error {
+	if tenant == "" {
+		return errors.New("empty tenant")
+	}
+
+	if builder == nil {
+		return errors.New("translations are not initialized")
+	}
+
+	if lazyCatalog != nil {
+		return errors.New("tenant overrides are not supported with lazy loading")
+	}
+
+	tenantsLoadMu.Lock()
+	defer tenantsLoadMu.Unlock()
+
+	_, err := internal.LoadTenant(ctx, builder, tenant, loader, true)
+	if err != nil && !internal.IsPartial(err) {
+		return errors.WithMessage(err, "load tenant overrides")
+	}
+
+	tenantsMu.Lock()
+	tenants[tenant] = loader
+	tenantsMu.Unlock()
+
+	return errors.WithMessage(err, "load tenant overrides")
+}
+
+// RemoveTenantOverrides makes the tenant use the base catalog. Printers of the tenant already in use keep
+// resolving its overrides.
+func RemoveTenantOverrides(tenant string) {
+	tenantsMu.Lock()
+	defer tenantsMu.Unlock()
+
+	delete(tenants, tenant)
+}
+
+// tenantLanguage returns the language of messages of the tenant, lang for tenants without overrides.
+func tenantLanguage(tenant string, lang language.Tag) language.Tag {
+	tenantsMu.RLock()
+	_, ok := tenants[tenant]
+	tenantsMu.RUnlock()
+
+	if !ok {
+		return lang
+	}
+
+	return internal.TenantLanguage(lang, tenant)
+}
+
+// GetTenantPrinter returns printer resolving overrides of the tenant first, the same as GetPrinter for tenants
+// without overrides.
+func GetTenantPrinter(tenant string, lang language.Tag) *message.Printer {
+	lang = supportedLanguage(lang)
+
+	if tag := tenantLanguage(tenant, lang); tag != lang {
+		return message.NewPrinter(tag, message.Catalog(builder))
+	}
+
+	return GetPrinter(lang)
+}
+
+// refreshTenants reloads overrides of tenants and makes messages of tenants follow base when it changed.
+func refreshTenants(ctx context.Context, baseChanged bool) (bool, error) {
+	tenantsMu.RLock()
+
+	current := make(map[string]internal.Loader, len(tenants))
+	for tenant, loader := range tenants {
+		current[tenant] = loader
+	}
+
+	tenantsMu.RUnlock()
+
+	tenantsLoadMu.Lock()
+	defer tenantsLoadMu.Unlock()
+
+	var (
+		changed bool
+		errs    internal.LoadErrors
+	)
+
+	for tenant, loader := range current {
+		tenantChanged, err := internal.LoadTenant(ctx, builder, tenant, loader, false)
+		changed = changed || tenantChanged
+
+		if err != nil {
+			var partial *internal.LoadErrors
+			if !errors.As(err, &partial) {
+				return changed, err
+			}
+
+			errs.Errors = append(errs.Errors, partial.Errors...)
+		}
+
+		if baseChanged {
+			if err := internal.RefreshTenant(builder, tenant); err != nil {
+				return changed, err
+			}
+		}
+	}
+
+	if len(errs.Errors) > 0 {
+		return changed, &errs
+	}
+
+	return changed, nil
+}
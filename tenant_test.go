@@ -0,0 +1,110 @@
+//go:build !i18n_extra
+
+package i18n_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	. "github.com/derfenix/goi18n"
+	"github.com/derfenix/goi18n/internal"
+)
+
+func TestTenantOverrides(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Init(internal.TestFS))
+
+	require.NoError(t, SetTenantOverrides(context.Background(), "acme", NewFSLoader(fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[{"key": "test", "translation": "Acme %s"}]`)},
+	})))
+
+	ctx := ContextWithLang(context.Background(), language.English)
+	assert.Equal(t, "Test of the beer", Sprintf(ctx, "test", "beer"))
+
+	tenantCtx := ContextWithTenant(ctx, "acme")
+	assert.Equal(t, "acme", TenantFromContext(tenantCtx))
+	assert.Equal(t, "Acme beer", Sprintf(tenantCtx, "test", "beer"))
+	assert.Equal(t, "Acme beer", NewError("test").WithParams("beer").Translate(tenantCtx))
+
+	// The order of language and tenant does not matter.
+	tenantCtx = ContextWithLang(ContextWithTenant(context.Background(), "acme"), language.English)
+	assert.Equal(t, "Acme beer", PrinterFromContext(tenantCtx).Sprintf("test", "beer"))
+
+	// Keys without overrides are resolved by the base catalog.
+	assert.Equal(t, "just pair of spiders", Sprintf(tenantCtx, "test plural", 2))
+
+	otherCtx := ContextWithTenant(ctx, "other")
+	assert.Equal(t, "Test of the beer", Sprintf(otherCtx, "test", "beer"))
+
+	// The empty tenant is the one of contexts without a tenant.
+	assert.Error(t, SetTenantOverrides(context.Background(), "", NewFSLoader(fstest.MapFS{})))
+	assert.Equal(t, []string{"en", "ru"}, languageNames(GetLanguages()))
+}
+
+func languageNames(languages []language.Tag) []string {
+	names := make([]string, len(languages))
+	for idx := range languages {
+		names[idx] = languages[idx].String()
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func TestTenantOverrides_Refresh(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Init(internal.TestFS))
+
+	files := fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "test", "translation": "Refreshed %s"},
+  {"key": "test plural", "digits": 1, "plural": {"one": "%.1f spider", "other": "%.1f spiders"}}
+]`)},
+	}
+
+	require.NoError(t, SetTenantOverrides(context.Background(), "refreshed", NewFSLoader(files)))
+
+	ctx := ContextWithTenant(ContextWithLang(context.Background(), language.English), "refreshed")
+	assert.Equal(t, "Refreshed beer", Sprintf(ctx, "test", "beer"))
+	assert.Equal(t, "2.0 spiders", Sprintf(ctx, "test plural", 2))
+	assert.Equal(t, "2.0 spiders", NewError("test plural").WithParams(2).Translate(ctx))
+
+	files["locales/en/active.json"] = &fstest.MapFile{Data: []byte(`[{"key": "test", "translation": "Refreshed %s"}]`)}
+
+	require.NoError(t, RefreshTranslations())
+	assert.Equal(t, "just pair of spiders", Sprintf(ctx, "test plural", 2))
+
+	RemoveTenantOverrides("refreshed")
+	assert.Equal(t, "Test of the beer", Sprintf(ctx, "test", "beer"))
+}
+
+func TestTenantOverrides_Named(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Init(internal.TestFS))
+
+	require.NoError(t, SetTenantOverrides(context.Background(), "named", NewFSLoader(fstest.MapFS{
+		"locales/en/active.json": &fstest.MapFile{Data: []byte(`[
+  {"key": "transition", "placeholders": ["from", "to"], "translation": "Moving from '{from}' to '{to}' is not allowed"}
+]`)},
+	})))
+
+	ctx := ContextWithTenant(ContextWithLang(context.Background(), language.English), "named")
+	params := map[string]string{"from": "draft", "to": "published"}
+
+	assert.Equal(t, "Moving from 'draft' to 'published' is not allowed", SprintfNamed(ctx, "transition", params))
+	assert.Equal(t, "Moving from 'draft' to 'published' is not allowed", NewError("transition").WithNamedParams(params).Translate(ctx))
+
+	// The base catalog is not affected.
+	baseCtx := ContextWithLang(context.Background(), language.English)
+	assert.Equal(t, "Transition from 'draft' to 'published' not allowed", SprintfNamed(baseCtx, "transition", params))
+}
@@ -2,6 +2,9 @@ package i18n
 
 import (
 	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"io"
 	"io/fs"
 	"net/http"
 	"sync"
@@ -16,10 +19,16 @@ import (
 
 var defaultLanguage = language.Russian
 
+var ErrStaleSnapshot = internal.ErrStaleSnapshot
+
 var (
 	initOnce sync.Once
 	builder  *catalog.Builder
 
+	lazyOptions *LazyOptions
+	lazyCatalog *internal.LazyCatalog
+
+	languagesMu           sync.RWMutex
 	supportedLanguages    []language.Tag
 	supportedLanguagesMap = map[string]struct{}{}
 )
@@ -32,6 +41,84 @@ func NewExternalLoaderWithClient(baseURL string, header http.Header, client *htt
 	return internal.NewExternalLoaderWithClient(baseURL, header, client)
 }
 
+type (
+	RetryPolicy  = internal.RetryPolicy
+	LanguageStat = internal.LanguageStat
+	CacheOptions = internal.CacheOptions
+	CachePolicy  = internal.CachePolicy
+	LoadError    = internal.LoadError
+	LoadErrors   = internal.LoadErrors
+
+	RequestDecorator     = internal.RequestDecorator
+	RequestDecoratorFunc = internal.RequestDecoratorFunc
+
+	Layer       = internal.Layer
+	LazyOptions = internal.LazyOptions
+	SQLLayout   = internal.SQLLayout
+
+	Locale       = internal.Locale
+	Message      = internal.Message
+	NestedPlural = internal.NestedPlural
+
+	ValidationPolicy = internal.ValidationPolicy
+	ValidationError  = internal.ValidationError
+	RejectedMessage  = internal.RejectedMessage
+)
+
+const (
+	CacheFail         = internal.CacheFail
+	CacheUse          = internal.CacheUse
+	CacheEmbeddedOnly = internal.CacheEmbeddedOnly
+)
+
+func DefaultRetryPolicy() RetryPolicy {
+	return internal.DefaultRetryPolicy()
+}
+
+func NewClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) *internal.ClientCredentials {
+	return internal.NewClientCredentials(tokenURL, clientID, clientSecret, scopes...)
+}
+
+// NewCompositeLoader chains layers, messages of later layers take precedence.
+func NewCompositeLoader(layers ...Layer) *internal.CompositeLoader {
+	return internal.NewCompositeLoader(layers...)
+}
+
+// NewFSLoader loads locales/<lang>/active.json files, the same layout Init uses.
+func NewFSLoader(files fs.ReadDirFS) *internal.FSLoader {
+	return internal.NewFSLoader(files)
+}
+
+// NewSQLLoader loads translations from a database table described by layout.
+func NewSQLLoader(db *sql.DB, layout SQLLayout) *internal.SQLLoader {
+	return internal.NewSQLLoader(db, layout)
+}
+
+// RegisterBundle adds locale files of a library under the namespace, keys become "<namespace>.<key>". Usually
+// called from init of the library, translations of the application override bundle ones.
+func RegisterBundle(namespace string, files fs.ReadDirFS, patterns ...string) {
+	internal.RegisterBundle(namespace, files, patterns...)
+}
+
+// MessageSource returns name of the CompositeLoader layer or bundle namespace which supplied the message.
+func MessageSource(lang language.Tag, key string) (string, bool) {
+	if builder == nil {
+		return "", false
+	}
+
+	return internal.MessageSource(builder, lang, key)
+}
+
+func NewHMACSigner(keyID string, secret []byte) *internal.HMACSigner {
+	return internal.NewHMACSigner(keyID, secret)
+}
+
+// SignBundle returns the detached signature of data for loaders with signatures, e.g. of a bundle of the
+// language, subject is the language tag.
+func SignBundle(key ed25519.PrivateKey, subject string, version int64, data []byte) ([]byte, error) {
+	return internal.SignBundle(key, subject, version, data)
+}
+
 type Translatable interface {
 	Translate(ctx context.Context) string
 }
@@ -45,6 +132,20 @@ func TryTranslate(ctx context.Context, obj interface{}) (string, bool) {
 }
 
 func Init(fs fs.ReadDirFS) error {
+	return InitContext(context.Background(), fs)
+}
+
+func InitContext(ctx context.Context, fs fs.ReadDirFS) error {
+	return InitLayersContext(ctx, fs)
+}
+
+// InitLayers initializes translations from several filesystems, e.g. embed.FS with os.DirFS overrides. Keys of
+// later layers override the same keys of earlier ones, changed files are re-read by RefreshTranslations.
+func InitLayers(layers ...fs.ReadDirFS) error {
+	return InitLayersContext(context.Background(), layers...)
+}
+
+func InitLayersContext(ctx context.Context, layers ...fs.ReadDirFS) error {
 	var err error
 
 	initOnce.Do(func() {
@@ -52,13 +153,59 @@ func Init(fs fs.ReadDirFS) error {
 			return
 		}
 
-		initCatalog, err := internal.InitBuilder(fs)
+		var initCatalog *catalog.Builder
+
+		if lazyOptions != nil {
+			lazyCatalog, err = internal.InitLazy(ctx, *lazyOptions, layers...)
+			if lazyCatalog != nil {
+				initCatalog = lazyCatalog.Root()
+			}
+		} else {
+			initCatalog, err = internal.InitBuilderLayers(ctx, layers...)
+		}
+
 		if err != nil {
 			err = errors.Wrap(err, "init catalog")
 
+			if initCatalog == nil {
+				return
+			}
+		}
+
+		builder = initCatalog
+
+		// Fill the local cache
+		GetLanguages()
+	})
+
+	return err
+}
+
+// InitLocales initializes translations from locales generated by cmd/goi18n-gen, producing the same catalog as
+// Init with the locale files.
+func InitLocales(locales []Locale) error {
+	return InitLocalesContext(context.Background(), locales)
+}
+
+func InitLocalesContext(ctx context.Context, locales []Locale) error {
+	var err error
+
+	initOnce.Do(func() {
+		if builder != nil {
 			return
 		}
 
+		var initCatalog *catalog.Builder
+
+		initCatalog, err = internal.InitBuilderLocales(ctx, locales)
+		if err != nil {
+			err = errors.Wrap(err, "init catalog")
+
+			if initCatalog == nil {
+				return
+			}
+		}
+
 		builder = initCatalog
 
 		// Fill the local cache
@@ -68,29 +215,106 @@ func Init(fs fs.ReadDirFS) error {
 	return err
 }
 
-func GetPrinter(lang language.Tag) *message.Printer {
-	_ = GetLanguages()
+// InitSnapshot initializes translations from a snapshot written by WriteSnapshot with the same version.
+// On error, e.g. ErrStaleSnapshot, Init may still be called. Fails if translations are already initialized.
+func InitSnapshot(r io.Reader, version string) error {
+	if builder != nil {
+		return errors.New("translations are already initialized")
+	}
 
-	base, _ := lang.Base()
-	if _, ok := supportedLanguagesMap[lang.String()]; !ok {
-		if _, ok = supportedLanguagesMap[base.String()]; !ok {
-			lang = defaultLanguage
+	initCatalog, err := internal.ReadSnapshot(r, version)
+	if err != nil {
+		return errors.Wrap(err, "read snapshot")
+	}
+
+	applied := false
+
+	initOnce.Do(func() {
+		if builder != nil {
+			return
+		}
+
+		builder, applied = initCatalog, true
+
+		// Fill the local cache
+		GetLanguages()
+	})
+
+	if !applied {
+		internal.ReleaseBuilder(initCatalog)
+
+		return errors.New("translations are already initialized")
+	}
+
+	return nil
+}
+
+// WriteSnapshot writes loaded translations in a compact binary form to be loaded by InitSnapshot. In lazy mode
+// every language is loaded first.
+func WriteSnapshot(w io.Writer, version string) error {
+	if builder == nil {
+		return errors.New("translations are not initialized")
+	}
+
+	if lazyCatalog != nil {
+		if err := lazyCatalog.LoadAll(context.Background()); err != nil {
+			return errors.WithMessage(err, "load all languages")
 		}
 	}
 
-	p := message.NewPrinter(lang, message.Catalog(builder))
+	return internal.WriteSnapshot(w, builder, version)
+}
+
+// GetPrinter returns printer of the language or the default one. Plural arguments passed to the printer are
+// not prepared, use PrepareArgsFor for messages with digits or Sprintf.
+func GetPrinter(lang language.Tag) *message.Printer {
+	lang = supportedLanguage(lang)
+
+	cat := builder
+	if lazyCatalog != nil {
+		cat = lazyCatalog.Builder(context.Background(), lang)
+	}
+
+	p := message.NewPrinter(lang, message.Catalog(cat))
 
 	return p
 }
 
+// supportedLanguage returns lang if it or its base is supported, the default language otherwise.
+func supportedLanguage(lang language.Tag) language.Tag {
+	_ = GetLanguages()
+
+	base, _ := lang.Base()
+	if !isSupported(lang.String()) && !isSupported(base.String()) {
+		return defaultLanguage
+	}
+
+	return lang
+}
+
 func GetLanguages() []language.Tag {
+	languagesMu.RLock()
+	languages := supportedLanguages
+	languagesMu.RUnlock()
+
+	if languages != nil {
+		return languages
+	}
+
+	if builder == nil {
+		return nil
+	}
+
+	languagesMu.Lock()
+	defer languagesMu.Unlock()
+
 	if supportedLanguages == nil {
-		if builder == nil {
-			return nil
+		if lazyCatalog != nil {
+			supportedLanguages = lazyCatalog.Languages()
+		} else {
+			supportedLanguages = internal.Languages(builder)
 		}
 
-		supportedLanguages = builder.Languages()
-
 		for idx := range supportedLanguages {
 			supportedLanguagesMap[supportedLanguages[idx].String()] = struct{}{}
 
@@ -102,16 +326,96 @@ func GetLanguages() []language.Tag {
 	return supportedLanguages
 }
 
+func isSupported(lang string) bool {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+
+	_, ok := supportedLanguagesMap[lang]
+
+	return ok
+}
+
+// resetLanguages drops the local cache of languages, so languages added by loaders become visible.
+func resetLanguages() {
+	languagesMu.Lock()
+	defer languagesMu.Unlock()
+
+	supportedLanguages = nil
+	supportedLanguagesMap = map[string]struct{}{}
+}
+
+// PrepareArgs prepares plural arguments of the key with digits of the default language, see PrepareArgsFor.
+func PrepareArgs(key string, args ...interface{}) []interface{} {
+	return PrepareArgsFor(defaultLanguage, key, args...)
+}
+
+// PrepareArgsFor converts plural arguments of the key to decimals according to digits of the language, Sprintf
+// and Error do it implicitly. Printers of GetPrinter do not, pass arguments prepared by PrepareArgsFor to them.
+func PrepareArgsFor(lang language.Tag, key string, args ...interface{}) []interface{} {
+	if builder == nil {
+		return args
+	}
+
+	return internal.PrepareArgs(builder, supportedLanguage(lang), key, args)
+}
+
+// RefreshTranslations re-reads locale files of the filesystems passed to Init and applies them if they were
+// changed, then reloads the external loader and tenant overrides. Keys removed from a filesystem fall back to
+// lower layers.
 func RefreshTranslations() error {
+	return RefreshTranslationsContext(context.Background())
+}
+
+func RefreshTranslationsContext(ctx context.Context) error {
+	_, err := RefreshTranslationsChanged(ctx)
+
+	return err
+}
+
+// RefreshTranslationsChanged refreshes translations and reports whether anything was changed.
+func RefreshTranslationsChanged(ctx context.Context) (bool, error) {
 	if builder == nil {
-		return nil
+		return false, nil
+	}
+
+	var (
+		changed bool
+		err     error
+	)
+
+	if lazyCatalog != nil {
+		changed, err = lazyCatalog.Refresh(ctx)
+	} else {
+		changed, err = internal.RefreshTranslations(ctx, builder)
 	}
 
-	if err := internal.RefreshTranslations(builder); err != nil {
-		return errors.WithMessage(err, "refresh translations")
+	if changed {
+		resetLanguages()
+		GetLanguages()
 	}
 
-	return nil
+	if err != nil && !internal.IsPartial(err) {
+		return changed, errors.WithMessage(err, "refresh translations")
+	}
+
+	tenantsChanged, tenantsErr := refreshTenants(ctx, changed)
+	changed = changed || tenantsChanged
+
+	if tenantsErr != nil {
+		if !internal.IsPartial(tenantsErr) {
+			return changed, errors.WithMessage(tenantsErr, "refresh tenant overrides")
+		}
+
+		if err == nil {
+			err = tenantsErr
+		}
+	}
+
+	if err != nil {
+		return changed, errors.WithMessage(err, "refresh translations")
+	}
+
+	return changed, nil
 }
 
 func SetExternalBuilder(b func(builder *catalog.Builder) error) {
@@ -121,3 +425,33 @@ func SetExternalBuilder(b func(builder *catalog.Builder) error) {
 func SetExternalLoader(loader internal.Loader) {
 	internal.SetExternalLoader(loader)
 }
+
+// SetContinueOnError makes Init continue past failing locale files and loaders, applying everything that
+// succeeded. Failures are returned as LoadErrors.
+func SetContinueOnError(enabled bool) {
+	internal.SetContinueOnError(enabled)
+}
+
+// SetLazyLoading makes Init only index locale files and languages discovered by the external loader, every
+// language is loaded on first use by GetPrinter. Must be called before Init, nil disables lazy loading. Tenant
+// overrides are not supported in lazy mode, SetTenantOverrides fails.
+func SetLazyLoading(options *LazyOptions) {
+	lazyOptions = options
+}
+
+// SetLayout sets patterns of locale file paths used by Init, e.g. "locales/{lang}.json", "active.{lang}.json" or
+// "modules/*/{lang}.json". Default is "locales/{lang}/active.json".
+func SetLayout(patterns ...string) error {
+	return internal.SetLayout(patterns...)
+}
+
+// SetValidationPolicy makes Init apply only messages of locale files accepted by the policy, rejected
+// messages are returned as LoadErrors. Nil disables validation.
+func SetValidationPolicy(policy *ValidationPolicy) {
+	internal.SetValidationPolicy(policy)
+}
+
+// LoaderWithoutContext adapts loader without context support to be used with SetExternalLoader.
+func LoaderWithoutContext(loader internal.LegacyLoader) internal.Loader {
+	return internal.LoaderWithoutContext(loader)
+}
@@ -3,6 +3,7 @@
 package i18n_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -104,3 +105,14 @@ func TestPrinter(t *testing.T) {
 		require.ElementsMatch(t, supportedLanguages, languages)
 	})
 }
+
+func TestInitSnapshot_Initialized(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Init(internal.TestFS))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, WriteSnapshot(&buf, "v1"))
+	assert.Error(t, InitSnapshot(&buf, "v1"))
+}